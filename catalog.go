@@ -0,0 +1,226 @@
+package e
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WithMessageID attaches a stable message ID, such as "user.not_found", used
+// to look up translations of the error's public message in a Catalog.
+// If err is not wrapped yet, it is wrapped with the current call site.
+func WithMessageID(err error, id string) error {
+	if err == nil {
+		return nil
+	}
+
+	var ew *ErrorWrapper
+	if !errors.As(err, &ew) {
+		ew = wrapWithSkip(err, 2, "", nil)
+		err = ew
+	}
+	ew.msgID = id
+	return err
+}
+
+// MessageID returns the message ID set with WithMessageID anywhere in the
+// chain of err, including the branches of joined errors.
+func MessageID(err error) (string, bool) {
+	if ew, ok := findWrapper(err, func(ew *ErrorWrapper) bool { return ew.msgID != "" }); ok {
+		return ew.msgID, true
+	}
+	return "", false
+}
+
+// Catalog maps message IDs to message templates per language tag.
+//
+// Templates refer to the fields of the error with {key} placeholders:
+//
+//	"user {user_id} not found"
+//
+// A Catalog is safe for concurrent use.
+type Catalog struct {
+	fallback string
+
+	mu       sync.RWMutex
+	messages map[string]map[string]string // language -> message ID -> template
+}
+
+// NewCatalog returns an empty catalog. Messages missing in the requested
+// language are looked up in the fallback language, e.g. "en".
+func NewCatalog(fallback string) *Catalog {
+	return &Catalog{
+		fallback: normalizeLang(fallback),
+		messages: map[string]map[string]string{},
+	}
+}
+
+// Add registers the template of the message id in lang.
+func (c *Catalog) Add(lang, id, template string) {
+	c.AddMessages(lang, map[string]string{id: template})
+}
+
+// AddMessages registers several templates, keyed by message ID, in lang.
+func (c *Catalog) AddMessages(lang string, messages map[string]string) {
+	lang = normalizeLang(lang)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := c.messages[lang]
+	if m == nil {
+		m = make(map[string]string, len(messages))
+		c.messages[lang] = m
+	}
+	for id, tmpl := range messages {
+		m[id] = tmpl
+	}
+}
+
+// LoadFile adds the messages of a JSON (.json) or YAML (.yaml, .yml) file.
+// The file maps language tags to message IDs to templates:
+//
+//	en:
+//	  user.not_found: "user {user_id} not found"
+//	de:
+//	  user.not_found: "Benutzer {user_id} nicht gefunden"
+func (c *Catalog) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return c.load(data, path)
+}
+
+// LoadFS is like LoadFile but reads the file from fsys, such as an embed.FS.
+func (c *Catalog) LoadFS(fsys fs.FS, path string) error {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return err
+	}
+	return c.load(data, path)
+}
+
+func (c *Catalog) load(data []byte, path string) error {
+	var file map[string]map[string]string
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("e: catalog %s: %w", path, err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("e: catalog %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("e: catalog %s: unsupported format %q", path, ext)
+	}
+
+	for lang, messages := range file {
+		c.AddMessages(lang, messages)
+	}
+	return nil
+}
+
+// lookup returns the template of id in lang, trying the exact tag, its base
+// language ("pt" for "pt-BR") and finally the fallback language.
+func (c *Catalog) lookup(id, lang string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	lang = normalizeLang(lang)
+	candidates := []string{lang}
+	if base, _, ok := strings.Cut(lang, "-"); ok {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, c.fallback)
+
+	for _, l := range candidates {
+		if tmpl, ok := c.messages[l][id]; ok {
+			return tmpl, true
+		}
+	}
+	return "", false
+}
+
+// Localize renders the public message of err in lang: the template of the
+// error's message ID with its fields interpolated. Without a translation it
+// falls back to the message set with WithPublicMessage, and then to "".
+//
+// Field values are rendered after the rules set with SetRedaction.
+func (c *Catalog) Localize(err error, lang string) string {
+	if id, ok := MessageID(err); ok {
+		if tmpl, ok := c.lookup(id, lang); ok {
+			return interpolate(tmpl, func(key string) (any, bool) { return publicFieldOf(err, key) })
+		}
+	}
+	return PublicMessage(err, "")
+}
+
+// defaultCatalog is the catalog used by the package-level Localize.
+var defaultCatalog atomic.Pointer[Catalog]
+
+// SetDefaultCatalog sets the catalog used by Localize.
+// A nil c makes Localize return only public messages.
+func SetDefaultCatalog(c *Catalog) {
+	defaultCatalog.Store(c)
+}
+
+// Localize renders the public message of err in lang using the catalog set
+// with SetDefaultCatalog. See Catalog.Localize.
+func Localize(err error, lang string) string {
+	if c := defaultCatalog.Load(); c != nil {
+		return c.Localize(err, lang)
+	}
+	return PublicMessage(err, "")
+}
+
+// publicFieldOf is FieldOf with the redaction rules applied.
+func publicFieldOf(err error, key string) (any, bool) {
+	v, ok := FieldOf(err, key)
+	if !ok {
+		return nil, false
+	}
+	return redactFields([]KeyValue{{Key: key, Value: v}})[0].any(), true
+}
+
+// interpolate replaces the {key} placeholders of tmpl with the values returned
+// by lookup. Placeholders without a value are kept as is.
+func interpolate(tmpl string, lookup func(key string) (any, bool)) string {
+	var sb strings.Builder
+	for {
+		open := strings.IndexByte(tmpl, '{')
+		if open == -1 {
+			break
+		}
+		end := strings.IndexByte(tmpl[open:], '}')
+		if end == -1 {
+			break
+		}
+		end += open
+
+		sb.WriteString(tmpl[:open])
+		if v, ok := lookup(tmpl[open+1 : end]); ok {
+			fmt.Fprint(&sb, v)
+		} else {
+			sb.WriteString(tmpl[open : end+1])
+		}
+		tmpl = tmpl[end+1:]
+	}
+	sb.WriteString(tmpl)
+	return sb.String()
+}
+
+// normalizeLang lower-cases a language tag and uses "-" as separator.
+func normalizeLang(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(lang, "_", "-"))
+}
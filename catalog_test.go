@@ -0,0 +1,91 @@
+package e_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+)
+
+func userNotFound(id int) error {
+	err := e.WrapWithFields(errors.New("sql: no rows in result set"), e.Field("user_id", id))
+	return e.WithMessageID(err, "user.not_found")
+}
+
+func TestMessageID(t *testing.T) {
+	_, ok := e.MessageID(errors.New("plain"))
+	assert.False(t, ok)
+
+	id, ok := e.MessageID(errors.Join(errors.New("a"), userNotFound(1)))
+	assert.True(t, ok)
+	assert.Equal(t, "user.not_found", id)
+}
+
+func TestCatalog_Localize(t *testing.T) {
+	c := e.NewCatalog("en")
+	c.Add("en", "user.not_found", "user {user_id} not found")
+	c.Add("de", "user.not_found", "Benutzer {user_id} nicht gefunden")
+	c.Add("pt-BR", "user.not_found", "usuário {user_id} não encontrado {missing}")
+
+	err := userNotFound(42)
+
+	assert.Equal(t, "Benutzer 42 nicht gefunden", c.Localize(err, "de"))
+	assert.Equal(t, "Benutzer 42 nicht gefunden", c.Localize(err, "de-AT"), "base language")
+	assert.Equal(t, "usuário 42 não encontrado {missing}", c.Localize(err, "pt_BR"))
+	assert.Equal(t, "user 42 not found", c.Localize(err, "fr"), "fallback language")
+}
+
+func TestCatalog_FallsBackToPublicMessage(t *testing.T) {
+	c := e.NewCatalog("en")
+
+	err := e.WithPublicMessage(userNotFound(1), "not found")
+	assert.Equal(t, "not found", c.Localize(err, "de"))
+	assert.Equal(t, "", c.Localize(errors.New("raw driver error"), "de"))
+}
+
+func TestCatalog_RedactsFields(t *testing.T) {
+	setRedaction(t, &e.RedactOpts{Keys: []string{"email"}})
+
+	c := e.NewCatalog("en")
+	c.Add("en", "user.exists", "user {email} already exists")
+
+	err := e.WithMessageID(e.WrapWithFields(errors.New("dup"), e.Field("email", "bob@example.com")), "user.exists")
+	assert.Equal(t, "user [REDACTED] already exists", c.Localize(err, "en"))
+}
+
+func TestCatalog_LoadFiles(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "messages.json")
+	yamlPath := filepath.Join(dir, "messages.yaml")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"en": {"user.not_found": "user {user_id} not found"}}`), 0o644))
+	require.NoError(t, os.WriteFile(yamlPath, []byte("es:\n  user.not_found: \"usuario {user_id} no encontrado\"\n"), 0o644))
+
+	c := e.NewCatalog("en")
+	require.NoError(t, c.LoadFile(jsonPath))
+	require.NoError(t, c.LoadFile(yamlPath))
+
+	err := userNotFound(7)
+	assert.Equal(t, "user 7 not found", c.Localize(err, "en"))
+	assert.Equal(t, "usuario 7 no encontrado", c.Localize(err, "es"))
+
+	assert.Error(t, c.LoadFile(filepath.Join(dir, "missing.json")))
+	assert.ErrorContains(t, c.LoadFS(fstest.MapFS{"m.toml": {Data: []byte("")}}, "m.toml"), "unsupported format")
+	assert.Error(t, c.LoadFS(fstest.MapFS{"bad.json": {Data: []byte("{")}}, "bad.json"))
+}
+
+func TestLocalize_DefaultCatalog(t *testing.T) {
+	err := userNotFound(3)
+	assert.Equal(t, "", e.Localize(err, "en"))
+
+	c := e.NewCatalog("en")
+	c.Add("en", "user.not_found", "user {user_id} not found")
+	e.SetDefaultCatalog(c)
+	t.Cleanup(func() { e.SetDefaultCatalog(nil) })
+
+	assert.Equal(t, "user 3 not found", e.Localize(err, "en-US"))
+}
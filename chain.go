@@ -0,0 +1,47 @@
+package e
+
+// findWrapper returns the first *ErrorWrapper in the chain of err, including
+// the branches of joined errors in order, for which match returns true.
+func findWrapper(err error, match func(*ErrorWrapper) bool) (*ErrorWrapper, bool) {
+	for err != nil {
+		if ew, ok := err.(*ErrorWrapper); ok && match(ew) {
+			return ew, true
+		}
+
+		children := unwrapAll(err)
+		switch len(children) {
+		case 0:
+			return nil, false
+		case 1:
+			err = children[0]
+		default:
+			for _, child := range children {
+				if ew, ok := findWrapper(child, match); ok {
+					return ew, true
+				}
+			}
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// unwrapAll returns the errors wrapped by err, supporting both
+// Unwrap() error and Unwrap() []error.
+func unwrapAll(err error) []error {
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if next := u.Unwrap(); next != nil {
+			return []error{next}
+		}
+	case interface{ Unwrap() []error }:
+		var out []error
+		for _, next := range u.Unwrap() {
+			if next != nil {
+				out = append(out, next)
+			}
+		}
+		return out
+	}
+	return nil
+}
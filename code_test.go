@@ -0,0 +1,91 @@
+package e_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+)
+
+var errUserNotFound = e.Define("user.not_found", e.NotFound, "user {user_id} not found")
+
+func findUser(id int) error {
+	return errUserNotFound.New(e.Field("user_id", id))
+}
+
+func TestDefine_New(t *testing.T) {
+	err := findUser(42)
+
+	assert.EqualError(t, err, "user 42 not found")
+	assert.True(t, errors.Is(err, errUserNotFound))
+	assert.True(t, errors.Is(fmt.Errorf("handler: %w", err), errUserNotFound))
+	assert.False(t, errors.Is(err, e.Define("user.not_found", e.NotFound, "user {user_id} not found")))
+
+	assert.Equal(t, e.NotFound, e.CodeOf(err))
+	id, _ := e.MessageID(err)
+	assert.Equal(t, "user.not_found", id)
+
+	v, _ := e.FieldOf(err, "user_id")
+	assert.Equal(t, 42, v)
+
+	var ew *e.ErrorWrapper
+	require.True(t, errors.As(err, &ew))
+	require.Len(t, ew.StackTrace(), 1)
+	assert.Contains(t, fmt.Sprintf("%+v", err), "findUser")
+}
+
+func TestDefine_Accessors(t *testing.T) {
+	assert.Equal(t, "user.not_found", errUserNotFound.ID())
+	assert.Equal(t, e.NotFound, errUserNotFound.Code())
+	assert.Equal(t, "user {user_id} not found", errUserNotFound.Error())
+}
+
+func TestDefine_Localize(t *testing.T) {
+	c := e.NewCatalog("en")
+	c.Add("de", "user.not_found", "Benutzer {user_id} nicht gefunden")
+
+	assert.Equal(t, "Benutzer 7 nicht gefunden", c.Localize(findUser(7), "de"))
+}
+
+func TestCode_Outputs(t *testing.T) {
+	err := e.WithCode(errors.New("boom"), e.Unavailable)
+
+	data, jerr := json.Marshal(err)
+	require.NoError(t, jerr)
+	assert.Contains(t, string(data), `"code":"unavailable"`)
+
+	logger, buf := newExpandingLogger(&e.SlogHandlerOpts{LiftCode: true})
+	logger.Error("failed", "err", err)
+	out := decodeLogLine(t, buf)
+	assert.Equal(t, "unavailable", out["code"])
+	assert.Equal(t, "unavailable", out["err"].(map[string]any)["code"])
+}
+
+func TestCode_Fingerprint(t *testing.T) {
+	a := e.Wrap(errors.New("boom"))
+	b := e.WithCode(e.Wrap(errors.New("boom")), e.Internal)
+
+	assert.Equal(t, "", string(e.CodeOf(a)))
+	assert.NotEqual(t, e.Fingerprint(a), e.Fingerprint(b))
+	assert.Equal(t, e.Fingerprint(findUser(1)), e.Fingerprint(findUser(2)))
+}
+
+func TestCode_SentryFingerprint(t *testing.T) {
+	srv, requests := newSentryServer(t)
+	sink, err := e.NewSentrySink(sentryDSN(srv), nil)
+	require.NoError(t, err)
+
+	uerr := findUser(1)
+	require.NoError(t, sink.Send(context.Background(), []e.Event{{Err: uerr, Time: time.Now()}}))
+
+	reqs := requests()
+	require.Len(t, reqs, 1)
+	assert.Equal(t, []string{"not_found", e.Fingerprint(uerr)}, reqs[0].event.Fingerprint)
+	assert.Equal(t, "not_found", reqs[0].event.Tags["code"])
+}
@@ -0,0 +1,239 @@
+package e
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sytallax/prettylog"
+)
+
+// ColorMode controls whether ANSI colors are used in human-readable output.
+type ColorMode int
+
+const (
+	// ColorAuto enables colors only when writing to a terminal and NO_COLOR is not set.
+	ColorAuto ColorMode = iota
+	// ColorAlways always enables colors.
+	ColorAlways
+	// ColorNever disables colors.
+	ColorNever
+)
+
+// enabled reports whether colors should be used when writing to w.
+func (m ColorMode) enabled(w io.Writer) bool {
+	switch m {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ANSI escape sequences used by the console output.
+const (
+	ansiReset   = "\033[0m"
+	ansiBold    = "\033[1m"
+	ansiRed     = "\033[91m"
+	ansiYellow  = "\033[93m"
+	ansiCyan    = "\033[36m"
+	ansiMagenta = "\033[35m"
+	ansiGray    = "\033[90m"
+)
+
+// painter applies ANSI colors when enabled.
+type painter bool
+
+func (p painter) paint(code, s string) string {
+	if !p || s == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// ConsoleHandlerOpts configures the handler returned by NewConsoleHandler.
+type ConsoleHandlerOpts struct {
+	// HandlerOptions are passed to the underlying prettylog handler.
+	HandlerOptions *slog.HandlerOptions
+
+	// Color selects colored output. Default: ColorAuto.
+	Color ColorMode
+
+	// Mode selects public or internal rendering for this handler.
+	// Default: RenderDefault, the mode set with SetRedaction.
+	Mode RenderMode
+}
+
+// NewConsoleHandler returns a development-mode slog.Handler writing
+// human-readable records to w, built on github.com/sytallax/prettylog.
+//
+// Attributes holding a wrapped error are taken out of the record and rendered
+// below it as an indented block: the message chain, one "file:line function"
+// line per frame (clickable in most terminals and IDEs) with its message,
+// and the fields as an aligned table. A nil opts selects the defaults.
+func NewConsoleHandler(w io.Writer, opts *ConsoleHandlerOpts) slog.Handler {
+	var o ConsoleHandlerOpts
+	if opts != nil {
+		o = *opts
+	}
+
+	color := o.Color.enabled(w)
+
+	popts := []prettylog.Option{prettylog.WithDestinationWriter(w)}
+	if color {
+		popts = append(popts, prettylog.WithColor())
+	}
+
+	return &consoleHandler{
+		inner: prettylog.New(o.HandlerOptions, popts...),
+		w:     w,
+		mu:    &sync.Mutex{},
+		color: painter(color),
+		mode:  o.Mode,
+	}
+}
+
+type consoleHandler struct {
+	inner  slog.Handler
+	w      io.Writer
+	mu     *sync.Mutex
+	color  painter
+	mode   RenderMode
+	prefix string
+	errs   []namedError
+}
+
+// namedError is an error attribute extracted from a record.
+type namedError struct {
+	key string
+	err error
+}
+
+// Enabled implements slog.Handler.
+func (h *consoleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *consoleHandler) Handle(ctx context.Context, r slog.Record) error {
+	errs := append([]namedError(nil), h.errs...)
+
+	out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		if err, ok := wrappedErrorAttr(a); ok {
+			errs = append(errs, namedError{key: h.prefix + a.Key, err: err})
+			return true
+		}
+		out.AddAttrs(a)
+		return true
+	})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.inner.Handle(ctx, out); err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	for _, ne := range errs {
+		writeConsoleError(&sb, ne.key, ne.err, h.color, redactionFor(h.mode))
+	}
+	if sb.Len() == 0 {
+		return nil
+	}
+	_, err := io.WriteString(h.w, sb.String())
+	return err
+}
+
+// WithAttrs implements slog.Handler.
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.errs = append([]namedError(nil), h.errs...)
+
+	rest := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if err, ok := wrappedErrorAttr(a); ok {
+			clone.errs = append(clone.errs, namedError{key: h.prefix + a.Key, err: err})
+			continue
+		}
+		rest = append(rest, a)
+	}
+
+	clone.inner = h.inner.WithAttrs(rest)
+	return &clone
+}
+
+// WithGroup implements slog.Handler.
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.inner = h.inner.WithGroup(name)
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
+
+// wrappedErrorAttr returns the error held by a if it wraps an *ErrorWrapper.
+func wrappedErrorAttr(a slog.Attr) (error, bool) {
+	kind := a.Value.Kind()
+	if kind != slog.KindAny && kind != slog.KindLogValuer {
+		return nil, false
+	}
+	err, ok := a.Value.Any().(error)
+	if !ok || err == nil {
+		return nil, false
+	}
+	var ew *ErrorWrapper
+	return err, errors.As(err, &ew)
+}
+
+// writeConsoleError renders err as an indented block for the console handler,
+// redacted with rules.
+func writeConsoleError(sb *strings.Builder, key string, err error, p painter, rules *RedactOpts) {
+	fmt.Fprintf(sb, "  %s %s\n", p.paint(ansiMagenta+ansiBold, key+":"), p.paint(ansiRed, rules.redactText(err.Error())))
+
+	// Message chain: every distinct text along the Unwrap chain.
+	last := err.Error()
+	for cur := errors.Unwrap(err); cur != nil; cur = errors.Unwrap(cur) {
+		if msg := cur.Error(); msg != last {
+			fmt.Fprintf(sb, "    %s %s\n", p.paint(ansiGray, "caused by:"), rules.redactText(msg))
+			last = msg
+		}
+	}
+
+	var ew *ErrorWrapper
+	errors.As(err, &ew)
+
+	for _, f := range ew.frames {
+		fmt.Fprintf(sb, "    %s %s", p.paint(ansiCyan, fmt.Sprintf("%s:%d", f.path(), f.line)), f.funcName)
+		if f.message != "" {
+			fmt.Fprintf(sb, " %s", p.paint(ansiYellow, "— "+rules.redactText(f.message)))
+		}
+		sb.WriteByte('\n')
+		writeSnippet(sb, "      ", f, p)
+	}
+
+	fields := ew.renderFields(rules)
+	width := 0
+	for _, kv := range fields {
+		width = max(width, len(kv.Key))
+	}
+	for _, kv := range fields {
+		pad := strings.Repeat(" ", width-len(kv.Key))
+		fmt.Fprintf(sb, "    %s%s = %v\n", p.paint(ansiGray, kv.Key), pad, kv.any())
+	}
+}
@@ -0,0 +1,63 @@
+package e_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whynot00/e"
+)
+
+func TestConsoleHandler_RendersErrorBlock(t *testing.T) {
+	var buf strings.Builder
+	logger := slog.New(e.NewConsoleHandler(&buf, nil))
+
+	err := e.WrapWithFields(
+		e.WrapWithMessage(errors.New("no rows"), "loading user"),
+		e.Field("user_id", 42),
+		e.Field("tenant", "foo"),
+	)
+	logger.Error("request failed", "err", fmt.Errorf("handler: %w", err), "path", "/users/42")
+
+	out := buf.String()
+	assert.NotContains(t, out, "\033[", "colors must be disabled for non-terminal output")
+	assert.Contains(t, out, "request failed")
+	assert.Contains(t, out, `"path": "/users/42"`)
+	assert.NotContains(t, out, `"err"`, "wrapped error must not be rendered as JSON")
+
+	assert.Contains(t, out, "  err: handler: no rows\n")
+	assert.Contains(t, out, "    caused by: no rows\n")
+	assert.Regexp(t, `console_test\.go:\d+ TestConsoleHandler_RendersErrorBlock — loading user\n`, out)
+	assert.Contains(t, out, "    user_id = 42\n")
+	assert.Contains(t, out, "    tenant  = foo\n")
+}
+
+func TestConsoleHandler_Color(t *testing.T) {
+	var buf strings.Builder
+	logger := slog.New(e.NewConsoleHandler(&buf, &e.ConsoleHandlerOpts{Color: e.ColorAlways}))
+
+	logger.Error("failed", "err", e.Wrap(errors.New("boom")))
+
+	assert.Contains(t, buf.String(), "\033[")
+}
+
+func TestConsoleHandler_WithAttrsAndGroup(t *testing.T) {
+	var buf strings.Builder
+	logger := slog.New(e.NewConsoleHandler(&buf, nil))
+
+	logger.WithGroup("job").With("cause", e.Wrap(errors.New("boom"))).Warn("retrying")
+
+	assert.Contains(t, buf.String(), "  job.cause: boom\n")
+}
+
+func TestConsoleHandler_PlainErrorsStayInline(t *testing.T) {
+	var buf strings.Builder
+	logger := slog.New(e.NewConsoleHandler(&buf, nil))
+
+	logger.Error("failed", "err", errors.New("plain"))
+
+	assert.Contains(t, buf.String(), `"err": "plain"`)
+}
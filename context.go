@@ -0,0 +1,77 @@
+package e
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ctxFieldsKey is the context key under which WithFields stores fields.
+type ctxFieldsKey struct{}
+
+// WithFields returns a copy of ctx carrying the given fields in addition to
+// any fields already stored in ctx. Fields are attached to errors by WrapCtx.
+func WithFields(ctx context.Context, fields ...Fields) context.Context {
+	parent := FieldsFromContext(ctx)
+
+	merged := Fields{list: parent.list}
+	for _, f := range fields {
+		merged.list = append(merged.list, f.list...)
+	}
+
+	return context.WithValue(ctx, ctxFieldsKey{}, merged)
+}
+
+// FieldsFromContext returns a copy of the fields stored in ctx by WithFields.
+func FieldsFromContext(ctx context.Context) Fields {
+	f, _ := ctx.Value(ctxFieldsKey{}).(Fields)
+	return Fields{list: append([]KeyValue(nil), f.list...)}
+}
+
+// WrapCtx is like Wrap but also attaches context information to the error:
+//   - the fields stored in ctx by WithFields;
+//   - trace_id and span_id of the OpenTelemetry span in ctx, if any;
+//   - context_cause and deadline_remaining when err is a context cancellation
+//     or deadline error.
+//
+// Keys already present on a wrapped error are not attached again, so WrapCtx
+// can be called at every level of the call stack.
+func WrapCtx(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	flds := FieldsFromContext(ctx)
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		flds.list = append(flds.list,
+			KeyValue{Key: "trace_id", Value: sc.TraceID().String()},
+			KeyValue{Key: "span_id", Value: sc.SpanID().String()},
+		)
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		if cause := context.Cause(ctx); cause != nil {
+			flds.list = append(flds.list, KeyValue{Key: "context_cause", Value: cause.Error()})
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			flds.list = append(flds.list, KeyValue{Key: "deadline_remaining", Value: time.Until(deadline)})
+		}
+	}
+
+	var ew *ErrorWrapper
+	if errors.As(err, &ew) {
+		existing := ew.Fields()
+		kept := flds.list[:0]
+		for _, kv := range flds.list {
+			if !existing.has(kv.Key) {
+				kept = append(kept, kv)
+			}
+		}
+		flds.list = kept
+	}
+
+	return wrapWithSkip(err, 2, "", &flds)
+}
@@ -0,0 +1,84 @@
+package e_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+)
+
+func TestWithFields_Accumulates(t *testing.T) {
+	ctx := e.WithFields(context.Background(), e.Field("request_id", "r-1"))
+	child := e.WithFields(ctx, e.Field("user_id", 42))
+
+	assert.Len(t, e.FieldsFromContext(ctx).List(), 1)
+
+	fs := e.FieldsFromContext(child)
+	assert.Equal(t, "r-1", fs.Get("request_id"))
+	assert.Equal(t, 42, fs.Get("user_id"))
+}
+
+func TestWrapCtx_AttachesContextFields(t *testing.T) {
+	ctx := e.WithFields(context.Background(), e.Field("request_id", "r-1"))
+
+	err := e.WrapCtx(ctx, errors.New("boom"))
+
+	var ew *e.ErrorWrapper
+	require.ErrorAs(t, err, &ew)
+	assert.Equal(t, "r-1", ew.Fields().Get("request_id"))
+	assert.Len(t, ew.StackTrace(), 1)
+}
+
+func TestWrapCtx_NoDuplicatesAcrossLevels(t *testing.T) {
+	ctx := e.WithFields(context.Background(), e.Field("request_id", "r-1"))
+
+	err := e.WrapCtx(ctx, errors.New("boom"))
+	err = e.WrapCtx(ctx, err)
+
+	ew := err.(*e.ErrorWrapper)
+	assert.Len(t, ew.Fields().List(), 1)
+	assert.Len(t, ew.StackTrace(), 2)
+}
+
+func TestWrapCtx_Nil(t *testing.T) {
+	assert.Nil(t, e.WrapCtx(context.Background(), nil))
+}
+
+func TestWrapCtx_Cancellation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	ctx, cancelCause := context.WithCancelCause(ctx)
+	defer cancel()
+	cancelCause(errors.New("client went away"))
+
+	err := e.WrapCtx(ctx, ctx.Err())
+
+	fs := err.(*e.ErrorWrapper).Fields()
+	assert.Equal(t, "client went away", fs.Get("context_cause"))
+
+	remaining, ok := fs.Get("deadline_remaining").(time.Duration)
+	require.True(t, ok)
+	assert.Greater(t, remaining, 59*time.Minute)
+}
+
+func TestWrapCtx_NoCancellationFieldsForOtherErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	fs := e.WrapCtx(ctx, errors.New("boom")).(*e.ErrorWrapper).Fields()
+	assert.Nil(t, fs.Get("deadline_remaining"))
+	assert.Nil(t, fs.Get("context_cause"))
+}
+
+func TestWrapCtx_TraceIDs(t *testing.T) {
+	_, start := newTestTracer(t)
+	ctx, end := start(context.Background())
+	defer end()
+
+	fs := e.WrapCtx(ctx, errors.New("boom")).(*e.ErrorWrapper).Fields()
+	assert.Len(t, fs.Get("trace_id"), 32)
+	assert.Len(t, fs.Get("span_id"), 16)
+}
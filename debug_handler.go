@@ -0,0 +1,189 @@
+package e
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+)
+
+// DebugHandler returns an http.Handler that renders the errors held by rec,
+// in the spirit of net/http/pprof. A nil rec means DefaultRecorder.
+//
+// GET serves an HTML page listing every recorded group with its count,
+// fingerprint and timestamps; each entry expands to the full stack trace,
+// per-frame messages and fields. Add "?format=json" to get the same data as JSON.
+//
+// POST with the form value "clear=1" empties the recorder.
+//
+// The handler is typically mounted under /debug/errors/:
+//
+//	http.Handle("/debug/errors/", e.DebugHandler(nil))
+func DebugHandler(rec *Recorder) http.Handler {
+	return &debugHandler{rec: rec}
+}
+
+type debugHandler struct {
+	rec *Recorder
+}
+
+func (h *debugHandler) recorder() *Recorder {
+	if h.rec == nil {
+		return DefaultRecorder
+	}
+	return h.rec
+}
+
+// ServeHTTP implements http.Handler.
+func (h *debugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	jsonFormat := r.FormValue("format") == "json"
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPost:
+		if r.FormValue("clear") != "1" {
+			http.Error(w, "unsupported action", http.StatusBadRequest)
+			return
+		}
+		h.recorder().Reset()
+		if jsonFormat {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		http.Redirect(w, r, r.URL.Path, http.StatusSeeOther)
+		return
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	entries := h.recorder().Entries()
+	views := make([]debugEntry, 0, len(entries))
+	for _, entry := range entries {
+		views = append(views, newDebugEntry(entry))
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	if jsonFormat {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(views); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := debugPageTemplate.Execute(w, views); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// debugEntry is the rendering model of one recorded group.
+type debugEntry struct {
+	Fingerprint string       `json:"fingerprint"`
+	Count       int          `json:"count"`
+	FirstSeen   time.Time    `json:"first_seen"`
+	LastSeen    time.Time    `json:"last_seen"`
+	Error       string       `json:"error"`
+	StackTrace  []debugFrame `json:"stack_trace"`
+	Fields      []debugField `json:"fields"`
+}
+
+// debugFrame is the rendering model of one stack frame.
+type debugFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Message  string `json:"message,omitempty"`
+}
+
+// debugField is the rendering model of one custom field.
+type debugField struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+// String formats the value for the HTML page.
+func (f debugField) String() string {
+	if raw, ok := f.Value.(json.RawMessage); ok {
+		return string(raw)
+	}
+	return fmt.Sprintf("%v", f.Value)
+}
+
+func newDebugEntry(entry RecordEntry) debugEntry {
+	view := debugEntry{
+		Fingerprint: entry.Fingerprint,
+		Count:       entry.Count,
+		FirstSeen:   entry.FirstSeen,
+		LastSeen:    entry.LastSeen,
+		Error:       redactText(entry.Sample.Error()),
+		StackTrace:  []debugFrame{},
+		Fields:      []debugField{},
+	}
+
+	var ew *ErrorWrapper
+	if !errors.As(entry.Sample, &ew) {
+		return view
+	}
+
+	for _, f := range ew.frames {
+		view.StackTrace = append(view.StackTrace, debugFrame{
+			Function: f.funcName,
+			File:     f.path(),
+			Line:     f.line,
+			Message:  redactText(f.message),
+		})
+	}
+	for _, kv := range ew.renderFields(redaction()) {
+		view.Fields = append(view.Fields, debugField{Key: kv.Key, Value: kv.safeJSON(0)})
+	}
+
+	return view
+}
+
+var debugPageTemplate = template.Must(template.New("errors").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>/debug/errors</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; }
+th, td { text-align: left; padding: 2px 8px; vertical-align: top; }
+code, pre { font-family: monospace; }
+details { margin: 4px 0 12px 0; }
+.msg { color: #555; font-style: italic; }
+</style>
+</head>
+<body>
+<h1>/debug/errors</h1>
+<p>{{len .}} distinct error(s). <a href="?format=json">JSON</a></p>
+<form method="post"><input type="hidden" name="clear" value="1"><button type="submit">Clear</button></form>
+{{range .}}
+<details>
+<summary><b>{{.Count}}×</b> <code>{{.Fingerprint}}</code> {{.Error}}
+<span class="msg">(first seen {{.FirstSeen.Format "2006-01-02 15:04:05"}}, last seen {{.LastSeen.Format "2006-01-02 15:04:05"}})</span></summary>
+{{if .StackTrace}}
+<h4>Stack trace</h4>
+<table>
+{{range .StackTrace}}<tr><td><code>{{.Function}}</code></td><td><code>{{.File}}:{{.Line}}</code></td><td class="msg">{{.Message}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .Fields}}
+<h4>Fields</h4>
+<table>
+{{range .Fields}}<tr><th>{{.Key}}</th><td><code>{{.String}}</code></td></tr>
+{{end}}</table>
+{{end}}
+</details>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,105 @@
+package e_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+)
+
+func newDebugServer(t *testing.T) (*httptest.Server, *e.Recorder) {
+	rec := e.NewRecorder(10)
+	rec.Record(e.WrapWithFields(
+		e.WrapWithMessage(loadUser(1), "rendering profile"),
+		e.Field("tenant", "foo"),
+	))
+	rec.Record(loadOrder(2))
+
+	srv := httptest.NewServer(e.DebugHandler(rec))
+	t.Cleanup(srv.Close)
+	return srv, rec
+}
+
+func TestDebugHandler_HTML(t *testing.T) {
+	srv, _ := newDebugServer(t)
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	page := string(body)
+	assert.Contains(t, page, "order 2 not found")
+	assert.Contains(t, page, "rendering profile")
+	assert.Contains(t, page, "tenant")
+	assert.Contains(t, page, "fingerprint_test.go")
+}
+
+func TestDebugHandler_JSON(t *testing.T) {
+	srv, _ := newDebugServer(t)
+
+	resp, err := http.Get(srv.URL + "?format=json")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var entries []struct {
+		Fingerprint string `json:"fingerprint"`
+		Count       int    `json:"count"`
+		Error       string `json:"error"`
+		StackTrace  []struct {
+			Function string `json:"function"`
+			Message  string `json:"message"`
+		} `json:"stack_trace"`
+		Fields []struct {
+			Key   string `json:"key"`
+			Value any    `json:"value"`
+		} `json:"fields"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&entries))
+	require.Len(t, entries, 2)
+
+	// Most recently recorded first.
+	assert.Equal(t, "order 2 not found", entries[0].Error)
+	assert.Equal(t, "user 1 not found", entries[1].Error)
+	assert.Equal(t, 1, entries[1].Count)
+	assert.NotEmpty(t, entries[1].Fingerprint)
+	require.Len(t, entries[1].StackTrace, 3)
+	assert.Equal(t, "rendering profile", entries[1].StackTrace[1].Message)
+	require.Len(t, entries[1].Fields, 1)
+	assert.Equal(t, "tenant", entries[1].Fields[0].Key)
+	assert.Equal(t, "foo", entries[1].Fields[0].Value)
+}
+
+func TestDebugHandler_Clear(t *testing.T) {
+	srv, rec := newDebugServer(t)
+
+	resp, err := http.PostForm(srv.URL+"?format=json", url.Values{"clear": {"1"}})
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Zero(t, rec.Len())
+}
+
+func TestDebugHandler_MethodNotAllowed(t *testing.T) {
+	srv, _ := newDebugServer(t)
+
+	req, err := http.NewRequest(http.MethodDelete, srv.URL, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
@@ -0,0 +1,19 @@
+// Package debugerrors serves the errors recorded by e.DefaultRecorder via HTTP.
+//
+// Like net/http/pprof, it is imported only for its side effect of registering
+// its handler on http.DefaultServeMux under /debug/errors/:
+//
+//	import _ "github.com/whynot00/e/debugerrors"
+//
+// To use a different mux or recorder, mount e.DebugHandler directly.
+package debugerrors
+
+import (
+	"net/http"
+
+	"github.com/whynot00/e"
+)
+
+func init() {
+	http.Handle("/debug/errors/", e.DebugHandler(nil))
+}
@@ -0,0 +1,319 @@
+package e
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// EncodeFallback selects how JSON outputs render a field value that
+// encoding/json cannot encode.
+type EncodeFallback int
+
+const (
+	// FallbackFmt renders the value with fmt's %+v verb. It is the default.
+	FallbackFmt EncodeFallback = iota
+
+	// FallbackPlaceholder renders a "!ERROR: <encoding error>" string,
+	// the way log/slog's JSON handler does.
+	FallbackPlaceholder
+)
+
+// Default encoding limits of field values.
+const (
+	DefaultFieldMaxDepth = 10
+	DefaultFieldMaxSize  = 16 << 10
+)
+
+// FieldEncodeOpts configures how field values are encoded by the JSON outputs
+// of the package: MarshalJSON, the sinks, Sentry extra data and the debug handler.
+//
+// Each field is encoded on its own, so a value that cannot be encoded (a
+// channel, a func, a cyclic value or a failing MarshalJSON) only affects that
+// field and never the error, its stack or the other fields.
+type FieldEncodeOpts struct {
+	// MaxDepth limits the nesting of objects and arrays in a field value,
+	// counted from the top-level fields. Deeper values are replaced by "…".
+	// Nested *ErrorWrapper values count as one level. Default: DefaultFieldMaxDepth.
+	MaxDepth int
+
+	// MaxSize limits the encoded size of a single field value in bytes.
+	// Larger values are replaced by a truncated string. Default: DefaultFieldMaxSize.
+	MaxSize int
+
+	// Fallback selects how values that cannot be encoded are rendered.
+	Fallback EncodeFallback
+}
+
+// fieldEncodeOpts holds the options set with SetFieldEncoding; nil means defaults.
+var fieldEncodeOpts atomic.Pointer[FieldEncodeOpts]
+
+// SetFieldEncoding sets how field values are encoded in JSON outputs.
+// A nil opts restores the defaults.
+func SetFieldEncoding(opts *FieldEncodeOpts) {
+	if opts == nil {
+		fieldEncodeOpts.Store(nil)
+		return
+	}
+	cp := *opts
+	fieldEncodeOpts.Store(&cp)
+}
+
+// encodeOpts returns the current options with defaults applied.
+func encodeOpts() FieldEncodeOpts {
+	var o FieldEncodeOpts
+	if p := fieldEncodeOpts.Load(); p != nil {
+		o = *p
+	}
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = DefaultFieldMaxDepth
+	}
+	if o.MaxSize <= 0 {
+		o.MaxSize = DefaultFieldMaxSize
+	}
+	return o
+}
+
+// renderOpts holds the options of one rendering of an error: the field
+// encoding and the redaction rules of the output, nil if it is not redacted.
+type renderOpts struct {
+	FieldEncodeOpts
+	rules *RedactOpts
+}
+
+// renderOptsFor returns the current options of an output rendering in mode.
+func renderOptsFor(mode RenderMode) renderOpts {
+	return renderOpts{FieldEncodeOpts: encodeOpts(), rules: redactionFor(mode)}
+}
+
+// safeJSON returns the value of kv ready to be embedded in a JSON document at
+// the given nesting depth. The result always encodes without error.
+func (kv KeyValue) safeJSON(depth int) any {
+	return kv.encodeJSON(depth, nil, renderOptsFor(RenderDefault))
+}
+
+// encodeJSON is safeJSON for a field of the errors in parents, which are
+// being encoded around it, rendered with o.
+func (kv KeyValue) encodeJSON(depth int, parents []*ErrorWrapper, o renderOpts) any {
+	v := kv.jsonValue()
+	if ew, ok := v.(*ErrorWrapper); ok {
+		return nestedErrorJSON(ew, depth+1, parents, o)
+	}
+	v = nestedJSON(v, depth+1, parents, o)
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return encodeFallback(v, err, o.FieldEncodeOpts)
+	}
+
+	if jsonDepth(data) > o.MaxDepth-depth {
+		data = pruneJSON(data, o.MaxDepth-depth)
+	}
+
+	if len(data) > o.MaxSize {
+		return truncate(string(data), o.MaxSize)
+	}
+	return json.RawMessage(data)
+}
+
+// nestedErrorJSON renders ew, found depth levels deep in the fields of the
+// errors in parents. An error nested in itself, or too deep, is rendered as
+// its message.
+func nestedErrorJSON(ew *ErrorWrapper, depth int, parents []*ErrorWrapper, o renderOpts) any {
+	if depth > o.MaxDepth || slices.Contains(parents, ew) {
+		return o.rules.redactText(ew.Error())
+	}
+	return ew.jsonMapAt(depth, parents, o)
+}
+
+// nestedJSON returns v, depth levels deep in the fields of the errors in
+// parents, with the *ErrorWrapper values in it rendered by nestedErrorJSON.
+func nestedJSON(v any, depth int, parents []*ErrorWrapper, o renderOpts) any {
+	if ew, ok := v.(*ErrorWrapper); ok {
+		return nestedErrorJSON(ew, depth, parents, o)
+	}
+	if nested, ok := embedErrors(reflect.ValueOf(v), depth, parents, o); ok {
+		return nested
+	}
+	return v
+}
+
+var (
+	errorWrapperType = reflect.TypeFor[*ErrorWrapper]()
+	marshalerType    = reflect.TypeFor[json.Marshaler]()
+)
+
+// embedErrors returns v with the *ErrorWrapper values held in its maps,
+// slices, arrays and pointers rendered by nestedErrorJSON, the container of
+// v being depth levels deep. ok is false if v holds no such value.
+//
+// Left to encoding/json, a nested wrapper would be encoded by MarshalJSON,
+// which starts over at depth 0 and recurses forever if the wrapper refers
+// back to the value, e.g. through a map in its own fields. Containers nested
+// deeper than the limit are replaced by "…", as pruneJSON would do.
+func embedErrors(v reflect.Value, depth int, parents []*ErrorWrapper, o renderOpts) (any, bool) {
+	if !v.IsValid() || v.Type().Implements(marshalerType) && v.Type() != errorWrapperType {
+		return nil, false
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, false
+		}
+		return embedErrors(v.Elem(), depth, parents, o)
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, false
+		}
+		if v.Type() == errorWrapperType {
+			return nestedErrorJSON(v.Interface().(*ErrorWrapper), depth, parents, o), true
+		}
+		// Only pointers to containers, so that every step of a cycle
+		// goes through a container and counts towards depth.
+		switch v.Type().Elem().Kind() {
+		case reflect.Map, reflect.Slice, reflect.Array:
+			return embedErrors(v.Elem(), depth, parents, o)
+		}
+
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String || !mayHoldError(v.Type().Elem()) {
+			return nil, false
+		}
+		if depth > o.MaxDepth {
+			return "…", true
+		}
+		out := make(map[string]any, v.Len())
+		found := false
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, ok := embedErrors(iter.Value(), depth+1, parents, o)
+			if !ok {
+				elem = iter.Value().Interface()
+			}
+			out[iter.Key().String()] = elem
+			found = found || ok
+		}
+		return out, found
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() || !mayHoldError(v.Type().Elem()) {
+			return nil, false
+		}
+		if depth > o.MaxDepth {
+			return "…", true
+		}
+		out := make([]any, v.Len())
+		found := false
+		for i := range v.Len() {
+			elem, ok := embedErrors(v.Index(i), depth+1, parents, o)
+			if !ok {
+				elem = v.Index(i).Interface()
+			}
+			out[i] = elem
+			found = found || ok
+		}
+		return out, found
+	}
+	return nil, false
+}
+
+// mayHoldError reports whether embedErrors may find an *ErrorWrapper in a
+// value of type t.
+func mayHoldError(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
+
+// encodeFallback renders v, which failed to encode with err.
+func encodeFallback(v any, err error, o FieldEncodeOpts) string {
+	// fmt does not detect cycles and would recurse forever.
+	var unsupported *json.UnsupportedValueError
+	cyclic := errors.As(err, &unsupported) && strings.Contains(unsupported.Str, "cycle")
+
+	if o.Fallback == FallbackPlaceholder || cyclic {
+		return "!ERROR: " + err.Error()
+	}
+	return truncate(fmt.Sprintf("%+v", v), o.MaxSize)
+}
+
+// truncate cuts s to at most size bytes, keeping it valid UTF-8.
+func truncate(s string, size int) string {
+	if len(s) <= size {
+		return s
+	}
+	return strings.ToValidUTF8(s[:size], "") + fmt.Sprintf("…(%d bytes truncated)", len(s)-size)
+}
+
+// jsonDepth returns the maximum nesting of objects and arrays in data.
+func jsonDepth(data []byte) int {
+	depth, maxDepth := 0, 0
+	inString, escaped := false, false
+	for _, c := range data {
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			switch c {
+			case '\\':
+				escaped = true
+			case '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+			maxDepth = max(maxDepth, depth)
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+	return maxDepth
+}
+
+// pruneJSON replaces the objects and arrays of data nested deeper than
+// limit levels with "…".
+func pruneJSON(data []byte, limit int) []byte {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return data
+	}
+	out, err := json.Marshal(prune(v, limit))
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+func prune(v any, limit int) any {
+	switch t := v.(type) {
+	case map[string]any:
+		if limit <= 0 {
+			return "…"
+		}
+		for k, child := range t {
+			t[k] = prune(child, limit-1)
+		}
+	case []any:
+		if limit <= 0 {
+			return "…"
+		}
+		for i, child := range t {
+			t[i] = prune(child, limit-1)
+		}
+	}
+	return v
+}
@@ -0,0 +1,131 @@
+package e_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+)
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) { return nil, errors.New("nope") }
+
+type node struct {
+	Name string
+	Next *node
+}
+
+func marshalMap(t *testing.T, err error) map[string]any {
+	t.Helper()
+	data, jerr := json.Marshal(err)
+	require.NoError(t, jerr)
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(data, &m))
+	return m
+}
+
+func TestMarshalJSON_UnencodableFields(t *testing.T) {
+	cyclic := &node{Name: "a"}
+	cyclic.Next = cyclic
+
+	err := e.WrapWithFields(errors.New("boom"),
+		e.Field("ch", make(chan int)),
+		e.Field("fn", func() {}),
+		e.Field("cyclic", cyclic),
+		e.Field("bad", failingMarshaler{}),
+		e.Field("ok", 1),
+	)
+
+	m := marshalMap(t, err)
+	assert.Equal(t, "boom", m["error"])
+	assert.NotEmpty(t, m["stack_trace"])
+	assert.Equal(t, float64(1), m["ok"])
+
+	assert.IsType(t, "", m["ch"])
+	assert.IsType(t, "", m["fn"])
+	assert.Equal(t, "{}", m["bad"])
+	assert.Contains(t, m["cyclic"], "!ERROR:")
+}
+
+func TestMarshalJSON_PlaceholderFallback(t *testing.T) {
+	e.SetFieldEncoding(&e.FieldEncodeOpts{Fallback: e.FallbackPlaceholder})
+	t.Cleanup(func() { e.SetFieldEncoding(nil) })
+
+	err := e.WrapWithFields(errors.New("boom"), e.Field("ch", make(chan int)))
+
+	m := marshalMap(t, err)
+	assert.Equal(t, "!ERROR: json: unsupported type: chan int", m["ch"])
+}
+
+func TestMarshalJSON_NestedErrorWrapper(t *testing.T) {
+	cause := e.WrapWithFields(errors.New("timeout"), e.Field("host", "db1"))
+	err := e.WrapWithFields(errors.New("boom"), e.Err("cause", cause))
+
+	m := marshalMap(t, err)
+	nested, ok := m["cause"].(map[string]any)
+	require.True(t, ok, "cause = %v", m["cause"])
+	assert.Equal(t, "timeout", nested["error"])
+	assert.Equal(t, "db1", nested["host"])
+	assert.NotEmpty(t, nested["stack_trace"])
+}
+
+func TestMarshalJSON_SelfReferencingError(t *testing.T) {
+	err := e.WrapWithFields(errors.New("boom"))
+	ew := err.(*e.ErrorWrapper)
+	_ = e.WrapWithFields(ew, e.Field("self", ew))
+
+	m := marshalMap(t, ew)
+	assert.Equal(t, "boom", m["error"])
+}
+
+func TestMarshalJSON_ErrorNestedInOwnFieldValue(t *testing.T) {
+	m := map[string]any{}
+	err := e.WrapWithFields(errors.New("boom"), e.Field("m", m))
+	m["self"] = err
+	m["list"] = []any{err, "x"}
+
+	out := marshalMap(t, err)
+	assert.Equal(t, "boom", out["error"])
+
+	nested, ok := out["m"].(map[string]any)
+	require.True(t, ok, "m = %v", out["m"])
+	assert.Equal(t, "boom", nested["self"])
+	assert.Equal(t, []any{"boom", "x"}, nested["list"])
+}
+
+func TestMarshalJSON_ErrorNestedInFieldValue(t *testing.T) {
+	cause := e.WrapWithFields(errors.New("timeout"), e.Field("host", "db1"))
+	err := e.WrapWithFields(errors.New("boom"), e.Field("causes", map[string]any{"db": cause}))
+
+	m := marshalMap(t, err)
+	causes, ok := m["causes"].(map[string]any)
+	require.True(t, ok, "causes = %v", m["causes"])
+	nested, ok := causes["db"].(map[string]any)
+	require.True(t, ok, "db = %v", causes["db"])
+	assert.Equal(t, "timeout", nested["error"])
+	assert.Equal(t, "db1", nested["host"])
+}
+
+func TestMarshalJSON_DepthAndSizeLimits(t *testing.T) {
+	e.SetFieldEncoding(&e.FieldEncodeOpts{MaxDepth: 2, MaxSize: 32})
+	t.Cleanup(func() { e.SetFieldEncoding(nil) })
+
+	deep := map[string]any{"a": map[string]any{"b": map[string]any{"c": 1}}}
+	err := e.WrapWithFields(errors.New("boom"),
+		e.Field("deep", deep),
+		e.Field("big", strings.Repeat("x", 100)),
+	)
+
+	m := marshalMap(t, err)
+	assert.Equal(t, map[string]any{"a": map[string]any{"b": "…"}}, m["deep"])
+
+	big, _ := m["big"].(string)
+	assert.True(t, strings.HasPrefix(big, `"xxx`), big)
+	assert.Contains(t, big, "bytes truncated")
+}
@@ -2,26 +2,39 @@ package e
 
 import (
 	"encoding/json"
+	"log/slog"
 )
 
 // Fields is an ordered collection of key–value pairs that can be attached
 // to an ErrorWrapper for structured logging and serialization.
 type Fields struct {
-	list []fieldKV
+	list []KeyValue
 }
 
-// fieldKV is an internal helper that stores one key/value pair.
-type fieldKV struct {
+// KeyValue is one key/value pair of Fields, as returned by Fields.List.
+type KeyValue struct {
 	Key   string
 	Value any
+
+	// val holds the value of fields built by the typed constructors
+	// (String, Int, ...) and FromAttrs, which is valid when typed is set.
+	val   slog.Value
+	typed bool
+
+	// isInt marks values built by Int: slog stores them as int64, and any
+	// converts them back to the int they were given as.
+	isInt bool
 }
 
 // List returns an independent copy of all key-value pairs stored
-// in Fields.  Modifications to the returned slice do not affect
+// in Fields, in order.  Modifications to the returned slice do not affect
 // the original Fields instance.
-func (f Fields) List() []fieldKV {
-	cp := make([]fieldKV, len(f.list))
+func (f Fields) List() []KeyValue {
+	cp := make([]KeyValue, len(f.list))
 	copy(cp, f.list)
+	for i := range cp {
+		cp[i].Value = cp[i].any()
+	}
 	return cp
 }
 
@@ -30,17 +43,22 @@ func (f Fields) List() []fieldKV {
 func (f Fields) Get(k string) any {
 	for _, kv := range f.list {
 		if kv.Key == k {
-			return kv.Value
+			return kv.any()
 		}
 	}
 	return nil
 }
 
+// has reports whether a field with key k exists.
+func (f Fields) has(k string) bool {
+	return f.index(k) != -1
+}
+
 // Field returns a new Fields containing a single key/value pair.
 // It is intended for use with WrapWithFields or as a starting point
-// for chaining additional fields.
+// for chaining additional fields with With.
 func Field(key string, value any) Fields {
-	return Fields{list: []fieldKV{{Key: key, Value: value}}}
+	return Fields{list: []KeyValue{{Key: key, Value: value}}}
 }
 
 // ErrorWrapper wraps an underlying error with stack-trace frames
@@ -48,64 +66,143 @@ func Field(key string, value any) Fields {
 type ErrorWrapper struct {
 	err    error
 	frames []frame
+
+	// fields holds the fields that belong to the error as a whole rather than
+	// to a wrap site, such as those attached by Supervise to a crash.
+	// Fields attached by WrapWithFields live on their frame.
 	fields *Fields
+
+	// panicked marks errors created from a panic (recovered in-process or
+	// parsed from a crashed child). Their frames are ordered innermost first.
+	panicked bool
+
+	// mode overrides the global message mode when not MessageDefault.
+	mode MessageMode
+
+	// public is the user-facing message set with WithPublicMessage.
+	public string
+
+	// msgID is the stable message ID set with WithMessageID.
+	msgID string
+
+	// code is the code set with WithCode or by Definition.New.
+	code Code
 }
 
-// Error returns the underlying error message.
-func (e *ErrorWrapper) Error() string { return e.err.Error() }
+// Error returns the underlying error message or, in MessageChain mode,
+// the frame messages followed by the underlying error message.
+func (e *ErrorWrapper) Error() string {
+	if e.messageMode() == MessageChain {
+		return e.chainText()
+	}
+	return e.err.Error()
+}
 
 // Unwrap implements errors.Unwrap, allowing errors.Is / errors.As to work.
 func (e *ErrorWrapper) Unwrap() error { return e.err }
 
-// StackTrace returns a shallow copy of the captured stack frames.
+// LogValue implements slog.LogValuer, so an ErrorWrapper logged as a plain
+// attribute value (logger.Error("msg", "err", err)) expands to the same group
+// that SlogGroup builds. Handlers resolve it lazily, only when the record is output.
+func (e *ErrorWrapper) LogValue() slog.Value {
+	return slog.GroupValue(slogAttrs(e)...)
+}
+
+// StackTrace returns a shallow copy of the captured stack frames,
+// with file paths rendered according to SetPathOpts.
 func (e *ErrorWrapper) StackTrace() []frame {
 	cp := make([]frame, len(e.frames))
 	copy(cp, e.frames)
+	for i := range cp {
+		cp[i].file = cp[i].path()
+	}
 	return cp
 }
 
-// Fields returns a deep copy of the custom fields attached to the error.
+// Fields returns a copy of all custom fields attached to the error, flattened
+// across wrap sites in the order they were attached (innermost first).
+// Use StackTrace to see which frame attached which field.
 // If no fields were attached, a zero value is returned.
 func (e *ErrorWrapper) Fields() Fields {
-	if e.fields == nil {
-		return Fields{}
+	return Fields{list: e.allFields()}
+}
+
+// allFields returns the error-wide fields followed by the fields of every
+// frame, oldest wrap site first.
+func (e *ErrorWrapper) allFields() []KeyValue {
+	var out []KeyValue
+	if e.fields != nil {
+		out = append(out, e.fields.list...)
 	}
-	cp := Fields{list: make([]fieldKV, len(e.fields.list))}
-	copy(cp.list, e.fields.list)
-	return cp
+	// Wrap prepends frames, so the oldest wrap site is last.
+	for i := len(e.frames) - 1; i >= 0; i-- {
+		out = append(out, e.frames[i].fields...)
+	}
+	return out
 }
 
 // MarshalJSON implements json.Marshaler and outputs a single JSON object
 // containing the original error text, stack trace and any custom fields.
 func (e *ErrorWrapper) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.jsonMap())
+}
+
+// jsonMap builds the object serialized by MarshalJSON.
+func (e *ErrorWrapper) jsonMap() map[string]any {
+	return e.jsonMapAt(0, nil, renderOptsFor(RenderDefault))
+}
+
+// jsonMapAt builds the object serialized by MarshalJSON for an error
+// nested depth levels deep in the fields of the errors in parents,
+// rendered with o.
+func (e *ErrorWrapper) jsonMapAt(depth int, parents []*ErrorWrapper, o renderOpts) map[string]any {
+	parents = append(parents[:len(parents):len(parents)], e)
+	value := func(kv KeyValue) any { return kv.encodeJSON(depth, parents, o) }
+
 	stack := make([]frameJSON, 0, len(e.frames))
 	for _, f := range e.frames {
 		stack = append(stack, frameJSON{
-			File:     f.file,
+			File:     f.path(),
 			Function: f.funcName,
 			Line:     f.line,
-			Message:  f.message,
+			Message:  o.rules.redactText(f.message),
+			Source:   f.sourceURL(),
+			Fields:   f.fieldsMap(o.rules, value),
 		})
 	}
 
 	out := map[string]any{
-		"error":       e.err.Error(),
+		"error":       o.rules.redactText(e.err.Error()),
+		"error_chain": o.rules.redactText(e.chainText()),
 		"stack_trace": stack,
+		"fingerprint": Fingerprint(e),
+	}
+	if e.code != "" {
+		out["code"] = e.code
+	}
+	if e.msgID != "" {
+		out["message_id"] = e.msgID
 	}
 
-	if e.fields != nil && len(e.fields.list) > 0 {
-		for _, kv := range e.fields.list {
-			out[kv.Key] = kv.Value
-		}
+	for _, kv := range e.renderFields(o.rules) {
+		out[kv.Key] = value(kv)
 	}
 
-	return json.Marshal(out)
+	return out
+}
+
+// renderFields returns allFields with the redaction rules applied,
+// for use by outputs.
+func (e *ErrorWrapper) renderFields(rules *RedactOpts) []KeyValue {
+	return rules.redactFields(e.allFields())
 }
 
 // frameJSON is the public representation of a single frame in the stack trace.
 type frameJSON struct {
-	File     string `json:"file"`
-	Function string `json:"function"`
-	Line     int    `json:"line"`
-	Message  string `json:"message,omitempty"`
+	File     string         `json:"file"`
+	Function string         `json:"function"`
+	Line     int            `json:"line"`
+	Message  string         `json:"message,omitempty"`
+	Source   string         `json:"source_url,omitempty"`
+	Fields   map[string]any `json:"fields,omitempty"`
 }
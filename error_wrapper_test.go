@@ -3,6 +3,7 @@ package e_test
 import (
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"strings"
 	"testing"
 
@@ -126,3 +127,157 @@ func TestMarshalJSON_WithMessage(t *testing.T) {
 		t.Errorf("expected to find custom message 'initialization failed' in stack trace")
 	}
 }
+
+func TestLogValue_ExpandsWithoutHelper(t *testing.T) {
+	var buf strings.Builder
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	err := e.WrapWithFields(
+		e.WrapWithMessage(errors.New("db failure"), "connecting to db"),
+		e.Field("retry", 3),
+	)
+	logger.Error("operation failed", "err", err)
+
+	var out struct {
+		Err struct {
+			ErrorText  string           `json:"error_text"`
+			StackTrace []map[string]any `json:"stack_trace"`
+			Retry      int              `json:"retry"`
+		} `json:"err"`
+	}
+	if errJSON := json.Unmarshal([]byte(buf.String()), &out); errJSON != nil {
+		t.Fatalf("invalid log output %q: %v", buf.String(), errJSON)
+	}
+
+	if out.Err.ErrorText != "db failure" {
+		t.Errorf("error_text = %q", out.Err.ErrorText)
+	}
+	if len(out.Err.StackTrace) != 2 {
+		t.Errorf("expected 2 frames, got %d", len(out.Err.StackTrace))
+	}
+	if out.Err.Retry != 3 {
+		t.Errorf("retry = %d, want 3", out.Err.Retry)
+	}
+}
+
+func TestLogValue_SelfReferencingError(t *testing.T) {
+	err := e.WrapWithFields(errors.New("boom"), e.Field("user_id", 7))
+	err = e.WrapWithFields(err, e.Err("self", err), e.Group("req", e.Err("cause", err)))
+
+	cause := e.WrapWithFields(errors.New("timeout"), e.Field("host", "db1"))
+	err = e.WrapWithFields(err, e.Err("cause", cause))
+
+	for name, h := range map[string]func(w *strings.Builder) slog.Handler{
+		"json": func(w *strings.Builder) slog.Handler { return slog.NewJSONHandler(w, nil) },
+		"text": func(w *strings.Builder) slog.Handler { return slog.NewTextHandler(w, nil) },
+	} {
+		var buf strings.Builder
+		slog.New(h(&buf)).Error("failed", "err", err)
+
+		if !strings.Contains(buf.String(), "boom") || !strings.Contains(buf.String(), "db1") {
+			t.Errorf("%s: unexpected output %s", name, buf.String())
+		}
+	}
+
+	var buf strings.Builder
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "err", err)
+
+	var out struct {
+		Err struct {
+			Self  string         `json:"self"`
+			Req   map[string]any `json:"req"`
+			Cause map[string]any `json:"cause"`
+		} `json:"err"`
+	}
+	if errJSON := json.Unmarshal([]byte(buf.String()), &out); errJSON != nil {
+		t.Fatalf("invalid log output %q: %v", buf.String(), errJSON)
+	}
+	if out.Err.Self != "boom" || out.Err.Req["cause"] != "boom" {
+		t.Errorf("self = %q, req = %v; want the message of the error", out.Err.Self, out.Err.Req)
+	}
+	if out.Err.Cause["error_text"] != "timeout" || out.Err.Cause["host"] != "db1" {
+		t.Errorf("cause = %v; want the expanded nested error", out.Err.Cause)
+	}
+}
+
+func TestLogValue_MatchesSlogGroup(t *testing.T) {
+	err := e.Wrap(errors.New("root"))
+
+	var viaValuer, viaGroup strings.Builder
+	slog.New(slog.NewJSONHandler(&viaValuer, nil)).Error("x", "error", err)
+	slog.New(slog.NewJSONHandler(&viaGroup, nil)).Error("x", e.SlogGroup(err))
+
+	// Drop the "time" attribute, which differs between the two records.
+	strip := func(s string) string { return s[strings.Index(s, `"level"`):] }
+	if strip(viaValuer.String()) != strip(viaGroup.String()) {
+		t.Errorf("LogValue output %s differs from SlogGroup output %s", viaValuer.String(), viaGroup.String())
+	}
+}
+
+func TestMarshalJSON_PerFrameFields(t *testing.T) {
+	inner := e.WrapWithFields(errors.New("boom"), e.Field("retry", 3))
+	outer := e.WrapWithFields(inner, e.Field("tenant", "foo"), e.Field("retry", 4))
+
+	data, err := json.Marshal(outer)
+	if err != nil {
+		t.Fatalf("failed to marshal error: %v", err)
+	}
+
+	var out struct {
+		StackTrace []struct {
+			Fields map[string]any `json:"fields"`
+		} `json:"stack_trace"`
+		Retry  float64 `json:"retry"`
+		Tenant string  `json:"tenant"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if len(out.StackTrace) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(out.StackTrace))
+	}
+	if got := out.StackTrace[0].Fields; got["tenant"] != "foo" || got["retry"] != float64(4) {
+		t.Errorf("outer frame fields = %v", got)
+	}
+	if got := out.StackTrace[1].Fields; len(got) != 1 || got["retry"] != float64(3) {
+		t.Errorf("inner frame fields = %v", got)
+	}
+	if out.Retry != 4 || out.Tenant != "foo" {
+		t.Errorf("flattened fields: retry=%v tenant=%q", out.Retry, out.Tenant)
+	}
+}
+
+func TestFields_FlattenedInAttachOrder(t *testing.T) {
+	inner := e.WrapWithFields(errors.New("boom"), e.Field("a", 1))
+	outer := e.WrapWithFields(e.Wrap(inner), e.Field("b", 2))
+
+	var keys []string
+	for k := range outer.(*e.ErrorWrapper).Fields().All() {
+		keys = append(keys, k)
+	}
+	if strings.Join(keys, ",") != "a,b" {
+		t.Errorf("flattened keys = %v; want [a b]", keys)
+	}
+}
+
+func TestSlogGroup_PerFrameFields(t *testing.T) {
+	err := e.WrapWithFields(errors.New("boom"), e.Field("user_id", 7))
+
+	var buf strings.Builder
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("x", e.SlogGroup(err))
+
+	var rec struct {
+		Error struct {
+			StackTrace []struct {
+				Fields map[string]any `json:"fields"`
+			} `json:"stack_trace"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &rec); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if len(rec.Error.StackTrace) != 1 || rec.Error.StackTrace[0].Fields["user_id"] != float64(7) {
+		t.Errorf("stack_trace = %+v", rec.Error.StackTrace)
+	}
+}
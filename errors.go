@@ -6,6 +6,7 @@ import (
 	"errors"
 	"log/slog"
 	"runtime"
+	"slices"
 )
 
 // Wrap returns an ErrorWrapper with the current call site.
@@ -48,31 +49,28 @@ func wrapWithSkip(err error, skip int, msg string, flds *Fields) *ErrorWrapper {
 
 	fr := frame{
 		funcName: simplifyFuncName(funcName),
+		function: funcName,
 		file:     file,
 		line:     line,
 		message:  msg,
 	}
+	if flds != nil && len(flds.list) > 0 {
+		if fieldSnapshots.Load() {
+			fr.fields = snapshotFields(flds.list)
+		} else {
+			fr.fields = append([]KeyValue(nil), flds.list...)
+		}
+	}
 
 	var ew *ErrorWrapper
 	if errors.As(err, &ew) {
 		ew.frames = append([]frame{fr}, ew.frames...)
-
-		if flds != nil && len(flds.list) > 0 {
-
-			if ew.fields == nil {
-				ew.fields = &Fields{}
-			}
-
-			ew.fields.list = append(ew.fields.list, flds.list...)
-		}
-
 		return ew
 	}
 
 	return &ErrorWrapper{
 		err:    err,
 		frames: []frame{fr},
-		fields: flds,
 	}
 }
 
@@ -99,47 +97,111 @@ func slogGroup(err error, name string) slog.Attr {
 		)
 	}
 
+	return slog.Attr{Key: name, Value: slog.GroupValue(slogAttrs(err)...)}
+}
+
+// slogAttrs builds the attributes of the group produced by SlogGroup
+// and ErrorWrapper.LogValue for a non-nil error.
+func slogAttrs(err error) []slog.Attr {
+	return slogAttrsAt(err, 0, nil, renderOptsFor(RenderDefault))
+}
+
+// slogAttrsAt builds the attributes of err nested depth levels deep in the
+// fields of the errors in parents, rendered with o. Nested wrappers are expanded here rather
+// than left to the handler, which would start over through LogValue and never
+// finish for an error that holds itself.
+func slogAttrsAt(err error, depth int, parents []*ErrorWrapper, o renderOpts) []slog.Attr {
 	var ew *ErrorWrapper
 	var baseErr = err
 	var frames []map[string]any
 
 	if errors.As(err, &ew) {
 		baseErr = ew.err
+		parents = append(parents[:len(parents):len(parents)], ew)
+		value := func(kv KeyValue) any { return nestedJSON(kv.jsonValue(), depth+1, parents, o) }
+
 		for _, f := range ew.frames {
 			entry := map[string]any{
 				"function": f.funcName,
-				"file":     f.file,
+				"file":     f.path(),
 				"line":     f.line,
 			}
 			if f.message != "" {
-				entry["message"] = f.message
+				entry["message"] = o.rules.redactText(f.message)
+			}
+			if src := f.sourceURL(); src != "" {
+				entry["source_url"] = src
+			}
+			if m := f.fieldsMap(o.rules, value); m != nil {
+				entry["fields"] = m
 			}
 			frames = append(frames, entry)
 
 		}
 	} else {
 		frames = append(frames, map[string]any{
-			"message": baseErr.Error(),
+			"message": o.rules.redactText(baseErr.Error()),
 		})
 	}
 
 	attrs := []slog.Attr{
-		slog.String("error_text", baseErr.Error()),
+		slog.String("error_text", o.rules.redactText(baseErr.Error())),
+	}
+
+	// Layers above the wrapper, such as fmt.Errorf("ctx: %w", ew), add text of
+	// their own: keep the full text and move the base text to its own key.
+	if ew != nil && err != error(ew) {
+		attrs = []slog.Attr{
+			slog.String("error_text", o.rules.redactText(err.Error())),
+			slog.String("base_error_text", o.rules.redactText(baseErr.Error())),
+		}
+	}
+
+	if ew != nil {
+		attrs = append(attrs, slog.String("error_chain", o.rules.redactText(ew.chainText())))
+	}
+
+	attrs = append(attrs, slog.String("fingerprint", Fingerprint(err)))
+
+	if ew != nil && ew.code != "" {
+		attrs = append(attrs, slog.String("code", string(ew.code)))
+	}
+	if ew != nil && ew.msgID != "" {
+		attrs = append(attrs, slog.String("message_id", ew.msgID))
 	}
 
 	if ew != nil && len(ew.frames) > 0 {
 		attrs = append(attrs, slog.Any("stack_trace", frames))
 	}
 
-	if ew != nil && ew.fields != nil && len(ew.fields.list) > 0 {
-		for _, kv := range ew.fields.list {
-			attrs = append(attrs, slog.Any(kv.Key, kv.Value))
+	if ew != nil {
+		for _, kv := range ew.renderFields(o.rules) {
+			attrs = append(attrs, slog.Attr{Key: kv.Key, Value: nestedSlogValue(kv.slogValue(), depth+1, parents, o)})
 		}
 	}
 
-	anyAttrs := make([]any, len(attrs))
-	for i, a := range attrs {
-		anyAttrs[i] = a
+	return attrs
+}
+
+// nestedSlogValue returns v, found depth levels deep in the fields of the
+// errors in parents, with the *ErrorWrapper values in it and in its groups
+// expanded. An error nested in itself, or too deep, becomes its message.
+func nestedSlogValue(v slog.Value, depth int, parents []*ErrorWrapper, o renderOpts) slog.Value {
+	switch v.Kind() {
+	case slog.KindLogValuer:
+		if ew, ok := v.Any().(*ErrorWrapper); ok {
+			if depth > o.MaxDepth || slices.Contains(parents, ew) {
+				return slog.StringValue(o.rules.redactText(ew.Error()))
+			}
+			return slog.GroupValue(slogAttrsAt(ew, depth, parents, o)...)
+		}
+	case slog.KindGroup:
+		group := v.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, a := range group {
+			attrs[i] = slog.Attr{Key: a.Key, Value: nestedSlogValue(a.Value, depth+1, parents, o)}
+		}
+		return slog.GroupValue(attrs...)
 	}
-	return slog.Group(name, anyAttrs...)
+	return v
 }
@@ -0,0 +1,264 @@
+package e
+
+import (
+	"encoding/json"
+	"iter"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MergePolicy decides what Fields.Merge does with keys present on both sides.
+type MergePolicy int
+
+const (
+	// MergeKeepAll keeps every pair, duplicates included.
+	MergeKeepAll MergePolicy = iota
+	// MergeKeepFirst keeps the value already present in the receiver.
+	MergeKeepFirst
+	// MergeKeepLast replaces the value in the receiver with the one from other,
+	// keeping the position of the original key.
+	MergeKeepLast
+)
+
+// Len returns the number of pairs in f.
+func (f Fields) Len() int { return len(f.list) }
+
+// With returns a copy of f with the pair key=value appended.
+// f itself is not modified, so a common base can be extended safely:
+//
+//	base := e.Field("service", "billing")
+//	err = e.WrapWithFields(err, base.With("invoice_id", id))
+func (f Fields) With(key string, value any) Fields {
+	return f.Merge(Field(key, value), MergeKeepAll)
+}
+
+// Merge returns a new Fields with the pairs of f followed by the pairs of
+// other, resolving keys present in both according to policy.
+// Duplicates already present within f or within other are left as is.
+func (f Fields) Merge(other Fields, policy MergePolicy) Fields {
+	out := Fields{list: make([]KeyValue, len(f.list), len(f.list)+len(other.list))}
+	copy(out.list, f.list)
+
+	for _, kv := range other.list {
+		if policy == MergeKeepAll {
+			out.list = append(out.list, kv)
+			continue
+		}
+		i := f.index(kv.Key)
+		switch {
+		case i == -1:
+			out.list = append(out.list, kv)
+		case policy == MergeKeepLast:
+			out.list[i] = kv
+		}
+	}
+	return out
+}
+
+// Delete returns a copy of f without the pairs whose key is one of keys.
+func (f Fields) Delete(keys ...string) Fields {
+	out := Fields{list: make([]KeyValue, 0, len(f.list))}
+	for _, kv := range f.list {
+		if !slices.Contains(keys, kv.Key) {
+			out.list = append(out.list, kv)
+		}
+	}
+	return out
+}
+
+// All returns an iterator over the pairs of f, in order.
+func (f Fields) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for _, kv := range f.list {
+			if !yield(kv.Key, kv.any()) {
+				return
+			}
+		}
+	}
+}
+
+// index returns the position of the first pair with key k, or -1.
+func (f Fields) index(k string) int {
+	for i, kv := range f.list {
+		if kv.Key == k {
+			return i
+		}
+	}
+	return -1
+}
+
+// FieldOf looks up key in the fields of every *ErrorWrapper in the chain of
+// err, including the branches of joined errors. The value attached last,
+// that is the one closest to the top of the call stack, wins.
+func FieldOf(err error, key string) (any, bool) {
+	var value any
+	_, ok := findWrapper(err, func(ew *ErrorWrapper) bool {
+		fields := ew.allFields()
+		for i := len(fields) - 1; i >= 0; i-- {
+			if fields[i].Key == key {
+				value = fields[i].any()
+				return true
+			}
+		}
+		return false
+	})
+	return value, ok
+}
+
+// String returns a Fields with a single string value.
+func String(key, value string) Fields {
+	return typedField(key, slog.StringValue(value))
+}
+
+// Int returns a Fields with a single int value.
+// Get and FieldOf return the value as an int, like Field does.
+func Int(key string, value int) Fields {
+	return Fields{list: []KeyValue{{Key: key, val: slog.IntValue(value), typed: true, isInt: true}}}
+}
+
+// Int64 returns a Fields with a single int64 value.
+func Int64(key string, value int64) Fields {
+	return typedField(key, slog.Int64Value(value))
+}
+
+// Uint64 returns a Fields with a single uint64 value.
+func Uint64(key string, value uint64) Fields {
+	return typedField(key, slog.Uint64Value(value))
+}
+
+// Float64 returns a Fields with a single float64 value.
+func Float64(key string, value float64) Fields {
+	return typedField(key, slog.Float64Value(value))
+}
+
+// Bool returns a Fields with a single bool value.
+func Bool(key string, value bool) Fields {
+	return typedField(key, slog.BoolValue(value))
+}
+
+// Duration returns a Fields with a single time.Duration value.
+func Duration(key string, value time.Duration) Fields {
+	return typedField(key, slog.DurationValue(value))
+}
+
+// Time returns a Fields with a single time.Time value.
+func Time(key string, value time.Time) Fields {
+	return typedField(key, slog.TimeValue(value))
+}
+
+// Err returns a Fields holding err. A nil err is stored as a nil value.
+//
+// When the error is rendered as JSON it is encoded with its MarshalJSON
+// method if it has one (as *ErrorWrapper does), and as its message otherwise.
+func Err(key string, err error) Fields {
+	return typedField(key, slog.AnyValue(err))
+}
+
+// Group returns a Fields holding the given fields nested under key.
+// slog outputs render it as a group, JSON outputs as an object.
+func Group(key string, fields ...Fields) Fields {
+	var attrs []slog.Attr
+	for _, f := range fields {
+		attrs = append(attrs, f.Attrs()...)
+	}
+	return typedField(key, slog.GroupValue(attrs...))
+}
+
+// FromAttrs converts slog attributes into Fields, keeping their order and
+// kinds, so attributes built for a logger can be attached to an error as is.
+func FromAttrs(attrs ...slog.Attr) Fields {
+	f := Fields{list: make([]KeyValue, 0, len(attrs))}
+	for _, a := range attrs {
+		f.list = append(f.list, KeyValue{Key: a.Key, val: a.Value, typed: true})
+	}
+	return f
+}
+
+// Attrs returns the fields as slog attributes, in order.
+// It is the inverse of FromAttrs.
+func (f Fields) Attrs() []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(f.list))
+	for _, kv := range f.list {
+		attrs = append(attrs, slog.Attr{Key: kv.Key, Value: kv.slogValue()})
+	}
+	return attrs
+}
+
+// Lazy returns a Fields whose value is computed by fn only when the error is
+// rendered (logged, marshaled, reported...), so expensive values cost nothing
+// for errors that are handled silently. fn is called at most once.
+func Lazy(key string, fn func() any) Fields {
+	return typedField(key, slog.AnyValue(lazyValue(sync.OnceValue(fn))))
+}
+
+// lazyValue defers a field value to render time. Implementing slog.LogValuer
+// lets slog handlers resolve it like any other lazy attribute.
+type lazyValue func() any
+
+// LogValue implements slog.LogValuer.
+func (fn lazyValue) LogValue() slog.Value { return slog.AnyValue(fn()) }
+
+func typedField(key string, v slog.Value) Fields {
+	return Fields{list: []KeyValue{{Key: key, val: v, typed: true}}}
+}
+
+// any returns the value of kv as an interface value.
+// Typed values are unboxed from their slog.Value and lazy values are resolved.
+// Other slog.LogValuer values, such as errors, are returned as is.
+func (kv KeyValue) any() any {
+	if !kv.typed {
+		return kv.Value
+	}
+	if kv.isInt {
+		return int(kv.val.Int64())
+	}
+	if lazy, ok := kv.val.Any().(lazyValue); ok {
+		return lazy()
+	}
+	return kv.val.Any()
+}
+
+// slogValue returns the value of kv as a slog.Value.
+func (kv KeyValue) slogValue() slog.Value {
+	if kv.typed {
+		return kv.val
+	}
+	return slog.AnyValue(kv.Value)
+}
+
+// jsonValue returns the value of kv in a form suitable for encoding/json:
+// groups become objects and errors without MarshalJSON become their message.
+func (kv KeyValue) jsonValue() any {
+	if kv.typed {
+		return jsonSlogValue(kv.val)
+	}
+	return jsonAny(kv.Value)
+}
+
+func jsonSlogValue(v slog.Value) any {
+	// Errors such as *ErrorWrapper implement slog.LogValuer; keep them as
+	// errors rather than their slog representation.
+	if err, ok := v.Any().(error); ok && v.Kind() == slog.KindLogValuer {
+		return jsonAny(err)
+	}
+	v = v.Resolve()
+	if v.Kind() != slog.KindGroup {
+		return jsonAny(v.Any())
+	}
+	m := make(map[string]any, len(v.Group()))
+	for _, a := range v.Group() {
+		m[a.Key] = jsonSlogValue(a.Value)
+	}
+	return m
+}
+
+func jsonAny(v any) any {
+	if err, ok := v.(error); ok {
+		if _, ok := err.(json.Marshaler); !ok {
+			return err.Error()
+		}
+	}
+	return v
+}
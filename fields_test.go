@@ -0,0 +1,212 @@
+package e_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+)
+
+func TestTypedFields_Get(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	err := e.WrapWithFields(errors.New("boom"),
+		e.String("user", "bob"),
+		e.Int("attempt", 3),
+		e.Bool("retryable", true),
+		e.Duration("elapsed", 1500*time.Millisecond),
+		e.Time("at", at),
+	)
+
+	var ew *e.ErrorWrapper
+	require.True(t, errors.As(err, &ew))
+	fs := ew.Fields()
+
+	assert.Equal(t, "bob", fs.Get("user"))
+	assert.Equal(t, 3, fs.Get("attempt"))
+	assert.Equal(t, true, fs.Get("retryable"))
+	assert.Equal(t, 1500*time.Millisecond, fs.Get("elapsed"))
+	assert.Equal(t, at, fs.Get("at"))
+	assert.Equal(t, 3, fs.List()[1].Value)
+
+	attempt, ok := e.FieldOf(err, "attempt")
+	require.True(t, ok)
+	assert.Equal(t, 3, attempt.(int))
+}
+
+var fieldSink e.Fields
+
+func TestTypedFields_NoBoxing(t *testing.T) {
+	n := len(t.Name()) << 20 // not a constant, so boxing it allocates
+	boxed := testing.AllocsPerRun(100, func() { fieldSink = e.Field("n", n) })
+	typed := testing.AllocsPerRun(100, func() { fieldSink = e.Int("n", n) })
+	assert.Less(t, typed, boxed, "Int must not box its value")
+}
+
+func TestTypedFields_JSON(t *testing.T) {
+	cause := errors.New("timeout")
+	err := e.WrapWithFields(errors.New("boom"),
+		e.Err("cause", cause),
+		e.Group("req", e.String("method", "GET"), e.Int("status", 504)),
+	)
+
+	data, jerr := json.Marshal(err)
+	require.NoError(t, jerr)
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(data, &m))
+	assert.Equal(t, "timeout", m["cause"])
+	assert.Equal(t, map[string]any{"method": "GET", "status": float64(504)}, m["req"])
+}
+
+func TestTypedFields_SlogGroup(t *testing.T) {
+	err := e.WrapWithFields(errors.New("boom"),
+		e.Duration("elapsed", time.Second),
+		e.Group("req", e.String("method", "GET")),
+	)
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", e.SlogGroup(err))
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	group := m["error"].(map[string]any)
+	assert.Equal(t, float64(time.Second), group["elapsed"])
+	assert.Equal(t, map[string]any{"method": "GET"}, group["req"])
+}
+
+func TestFromAttrs_RoundTrip(t *testing.T) {
+	attrs := []slog.Attr{
+		slog.String("user", "bob"),
+		slog.Int("attempt", 2),
+		slog.Group("req", slog.String("method", "POST")),
+	}
+
+	fs := e.FromAttrs(attrs...)
+	require.Len(t, fs.List(), 3)
+	assert.Equal(t, "bob", fs.Get("user"))
+
+	back := fs.Attrs()
+	require.Len(t, back, 3)
+	for i := range attrs {
+		assert.True(t, attrs[i].Equal(back[i]), "attr %d", i)
+	}
+}
+
+func TestAttrs_UntypedField(t *testing.T) {
+	attrs := e.Field("retry", 3).Attrs()
+	require.Len(t, attrs, 1)
+	assert.Equal(t, "retry", attrs[0].Key)
+	assert.Equal(t, slog.KindInt64, attrs[0].Value.Kind())
+	assert.Equal(t, int64(3), attrs[0].Value.Int64())
+}
+
+func TestFields_With(t *testing.T) {
+	base := e.Field("service", "billing")
+	a := base.With("invoice", 1)
+	b := base.With("invoice", 2)
+
+	assert.Equal(t, 1, base.Len())
+	assert.Equal(t, 1, a.Get("invoice"))
+	assert.Equal(t, 2, b.Get("invoice"))
+	assert.Equal(t, "billing", b.Get("service"))
+}
+
+func TestFields_Merge(t *testing.T) {
+	f := e.Field("a", 1).With("b", 2)
+	other := e.Field("b", 3).With("c", 4)
+
+	keys := func(fs e.Fields) (out []string) {
+		for k, v := range fs.All() {
+			out = append(out, k+"="+fmt.Sprint(v))
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"a=1", "b=2", "b=3", "c=4"}, keys(f.Merge(other, e.MergeKeepAll)))
+	assert.Equal(t, []string{"a=1", "b=2", "c=4"}, keys(f.Merge(other, e.MergeKeepFirst)))
+	assert.Equal(t, []string{"a=1", "b=3", "c=4"}, keys(f.Merge(other, e.MergeKeepLast)))
+	assert.Equal(t, 2, f.Len(), "receiver must not change")
+}
+
+func TestFields_Delete(t *testing.T) {
+	f := e.Field("a", 1).With("b", 2).With("c", 3)
+
+	d := f.Delete("a", "c")
+	assert.Equal(t, 1, d.Len())
+	assert.Equal(t, 2, d.Get("b"))
+	assert.Equal(t, 3, f.Len())
+}
+
+func TestFields_AllStopsEarly(t *testing.T) {
+	f := e.Field("a", 1).With("b", 2)
+
+	var seen []string
+	for k := range f.All() {
+		seen = append(seen, k)
+		break
+	}
+	assert.Equal(t, []string{"a"}, seen)
+}
+
+func TestFieldOf(t *testing.T) {
+	inner := e.WrapWithFields(errors.New("boom"), e.Field("user_id", 1), e.Field("tenant", "foo"))
+	outer := e.WrapWithFields(inner, e.Field("user_id", 2))
+	joined := errors.Join(errors.New("other"), fmt.Errorf("load: %w", outer))
+
+	v, ok := e.FieldOf(joined, "user_id")
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+
+	v, ok = e.FieldOf(joined, "tenant")
+	assert.True(t, ok)
+	assert.Equal(t, "foo", v)
+
+	_, ok = e.FieldOf(joined, "missing")
+	assert.False(t, ok)
+
+	_, ok = e.FieldOf(nil, "user_id")
+	assert.False(t, ok)
+}
+
+func TestFieldOf_ErrField(t *testing.T) {
+	cause := e.WrapWithFields(errors.New("timeout"), e.Field("host", "db1"))
+	outer := e.WrapWithFields(errors.New("boom"), e.Err("cause", cause))
+
+	v, ok := e.FieldOf(outer, "cause")
+	require.True(t, ok)
+	assert.Same(t, cause, v)
+
+	var ew *e.ErrorWrapper
+	require.True(t, errors.As(outer, &ew))
+	assert.Same(t, cause, ew.Fields().Get("cause"))
+	assert.Contains(t, fmt.Sprintf("%+v", outer), "cause = timeout")
+}
+
+func TestLazy_EvaluatedAtRenderTime(t *testing.T) {
+	calls := 0
+	err := e.WrapWithFields(errors.New("boom"), e.Lazy("dump", func() any {
+		calls++
+		return map[string]int{"rows": 3}
+	}))
+	assert.Equal(t, 0, calls, "must not be evaluated at wrap time")
+
+	data, jerr := json.Marshal(err)
+	require.NoError(t, jerr)
+	assert.Contains(t, string(data), `"dump":{"rows":3}`)
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "err", err)
+	assert.Contains(t, buf.String(), `"dump":{"rows":3}`)
+
+	v, ok := e.FieldOf(err, "dump")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]int{"rows": 3}, v)
+	assert.Equal(t, 1, calls, "evaluated at most once")
+}
@@ -0,0 +1,97 @@
+package e
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go/build"
+	"path/filepath"
+	"strings"
+)
+
+// Fingerprint returns a stable identifier for err, suitable for grouping
+// identical errors in logs and error trackers.
+//
+// The fingerprint is computed from the type of the underlying error, its code
+// and message ID, if any, and the normalized stack trace (function names and
+// file paths, without line numbers or GOPATH/GOROOT prefixes). The error
+// message is deliberately ignored, so errors whose text contains IDs or other
+// variable data still group together.
+//
+// Errors without a stack trace, such as those never passed to Wrap, have
+// only their type to go by, which would put every errors.New in one group;
+// their message is used instead of the stack.
+//
+// It returns an empty string for a nil error.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	baseErr := err
+	var frames []frame
+
+	var ew *ErrorWrapper
+	if errors.As(err, &ew) {
+		baseErr = ew.err
+		frames = ew.frames
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "type:%s\n", errorType(baseErr))
+	if ew != nil && ew.code != "" {
+		fmt.Fprintf(h, "code:%s\n", ew.code)
+	}
+	if ew != nil && ew.msgID != "" {
+		fmt.Fprintf(h, "id:%s\n", ew.msgID)
+	}
+	for _, f := range frames {
+		fmt.Fprintf(h, "frame:%s@%s\n", f.funcName, normalizeFingerprintPath(f))
+	}
+	if len(frames) == 0 {
+		fmt.Fprintf(h, "message:%s\n", err.Error())
+	}
+
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// normalizeFingerprintPath strips host-specific prefixes from the source path
+// of f, so the same code produces the same fingerprint on every machine.
+func normalizeFingerprintPath(f frame) string {
+	file := filepath.ToSlash(f.file)
+
+	if rel, ok := moduleRelativePath(file, f.function); ok {
+		return rel
+	}
+
+	// Module cache: /home/ci/go/pkg/mod/github.com/foo/bar@v1.2.3/x.go → github.com/foo/bar/x.go
+	if i := strings.LastIndex(file, "/pkg/mod/"); i != -1 {
+		return stripModuleVersion(file[i+len("/pkg/mod/"):])
+	}
+
+	for _, root := range []string{build.Default.GOROOT, build.Default.GOPATH} {
+		if root == "" {
+			continue
+		}
+		prefix := strings.TrimSuffix(filepath.ToSlash(root), "/") + "/src/"
+		if strings.HasPrefix(file, prefix) {
+			return file[len(prefix):]
+		}
+	}
+
+	return file
+}
+
+// stripModuleVersion removes the "@version" suffix from the module element of a path.
+func stripModuleVersion(path string) string {
+	at := strings.Index(path, "@")
+	if at == -1 {
+		return path
+	}
+	rest := path[at:]
+	if slash := strings.Index(rest, "/"); slash != -1 {
+		return path[:at] + rest[slash:]
+	}
+	return path[:at]
+}
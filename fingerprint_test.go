@@ -0,0 +1,71 @@
+package e_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+)
+
+func loadUser(id int) error {
+	return e.Wrap(fmt.Errorf("user %d not found", id))
+}
+
+func loadOrder(id int) error {
+	return e.Wrap(fmt.Errorf("order %d not found", id))
+}
+
+func TestFingerprint_IgnoresMessage(t *testing.T) {
+	assert.Equal(t, e.Fingerprint(loadUser(1)), e.Fingerprint(loadUser(2)))
+}
+
+func TestFingerprint_DiffersByStack(t *testing.T) {
+	assert.NotEqual(t, e.Fingerprint(loadUser(1)), e.Fingerprint(loadOrder(1)))
+}
+
+func TestFingerprint_DiffersByType(t *testing.T) {
+	plain := e.Fingerprint(errors.New("x"))
+	typed := e.Fingerprint(&json.SyntaxError{})
+
+	assert.NotEqual(t, plain, typed)
+}
+
+func TestFingerprint_UnwrappedErrors(t *testing.T) {
+	// Without frames the message is all that tells errors of a type apart.
+	assert.NotEqual(t, e.Fingerprint(errors.New("a")), e.Fingerprint(errors.New("b")))
+	assert.Equal(t, e.Fingerprint(errors.New("a")), e.Fingerprint(errors.New("a")))
+
+	errNotFound := errors.New("not found")
+	assert.Equal(t, e.Fingerprint(errNotFound), e.Fingerprint(errNotFound))
+	assert.NotEqual(t, e.Fingerprint(errNotFound), e.Fingerprint(fmt.Errorf("load: %w", errNotFound)))
+}
+
+func TestFingerprint_Nil(t *testing.T) {
+	assert.Empty(t, e.Fingerprint(nil))
+}
+
+func TestFingerprint_InOutputs(t *testing.T) {
+	err := loadUser(7)
+	fp := e.Fingerprint(err)
+	require.NotEmpty(t, fp)
+
+	var found bool
+	for _, a := range e.SlogGroup(err).Value.Group() {
+		if a.Key == "fingerprint" {
+			found = true
+			assert.Equal(t, fp, a.Value.String())
+		}
+	}
+	assert.True(t, found, "fingerprint missing from slog group")
+
+	data, errMarshal := json.Marshal(err)
+	require.NoError(t, errMarshal)
+
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, fp, out["fingerprint"])
+}
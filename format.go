@@ -0,0 +1,128 @@
+package e
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FormatOpts configures Format.
+type FormatOpts struct {
+	// MaxDepth limits how many layers of the error tree are rendered.
+	// Zero means no limit.
+	MaxDepth int
+
+	// TrimPrefixes are removed from the beginning of file paths
+	// (the first matching prefix wins).
+	TrimPrefixes []string
+
+	// Color enables ANSI colors.
+	Color bool
+
+	// Mode selects public or internal rendering for this report.
+	// Default: RenderDefault, the mode set with SetRedaction.
+	Mode RenderMode
+}
+
+// Format returns a multi-line, human-readable report of err.
+//
+// It walks the whole Unwrap chain, including the branches of errors created
+// with errors.Join or fmt.Errorf with several %w verbs, and shows for each
+// layer its Go type and message, plus the frames and fields of every
+// *ErrorWrapper layer. A nil opts selects the defaults.
+func Format(err error, opts *FormatOpts) string {
+	if err == nil {
+		return "<nil>"
+	}
+
+	var o FormatOpts
+	if opts != nil {
+		o = *opts
+	}
+
+	f := &formatter{opts: o, p: painter(o.Color), rules: redactionFor(o.Mode)}
+	f.layer(err, "", "", 1)
+	return strings.TrimSuffix(f.sb.String(), "\n")
+}
+
+// Format implements fmt.Formatter.
+//
+// %v and %s print the error message, %q prints it quoted, and %+v prints
+// the full report produced by Format with the default options.
+func (e *ErrorWrapper) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, Format(e, nil))
+			return
+		}
+		io.WriteString(s, e.Error())
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(%T=%s)", verb, e, e.Error())
+	}
+}
+
+// formatter accumulates the report built by Format.
+type formatter struct {
+	sb    strings.Builder
+	opts  FormatOpts
+	p     painter
+	rules *RedactOpts
+}
+
+// layer renders err and its children. head prefixes the layer's first line,
+// body prefixes every following line.
+func (f *formatter) layer(err error, head, body string, depth int) {
+	fmt.Fprintf(&f.sb, "%s%s %s\n", head, f.p.paint(ansiRed, errorType(err)+":"), f.rules.redactText(err.Error()))
+
+	if ew, ok := err.(*ErrorWrapper); ok {
+		for _, fr := range ew.frames {
+			fmt.Fprintf(&f.sb, "%s    at %s %s", body, f.p.paint(ansiCyan, fmt.Sprintf("%s:%d", f.trim(fr.path()), fr.line)), fr.funcName)
+			if fr.message != "" {
+				fmt.Fprintf(&f.sb, " %s", f.p.paint(ansiYellow, "— "+f.rules.redactText(fr.message)))
+			}
+			f.sb.WriteByte('\n')
+			writeSnippet(&f.sb, body+"         ", fr, f.p)
+			for _, kv := range f.rules.redactFields(fr.fields) {
+				fmt.Fprintf(&f.sb, "%s         %s = %v\n", body, f.p.paint(ansiGray, kv.Key), kv.any())
+			}
+		}
+		if ew.fields != nil {
+			for _, kv := range f.rules.redactFields(ew.fields.list) {
+				fmt.Fprintf(&f.sb, "%s    %s = %v\n", body, f.p.paint(ansiGray, kv.Key), kv.any())
+			}
+		}
+	}
+
+	children := unwrapAll(err)
+	if len(children) == 0 {
+		return
+	}
+
+	if f.opts.MaxDepth > 0 && depth >= f.opts.MaxDepth {
+		fmt.Fprintf(&f.sb, "%s└─ %s\n", body, f.p.paint(ansiGray, "… (max depth reached)"))
+		return
+	}
+
+	for i, child := range children {
+		if i == len(children)-1 {
+			f.layer(child, body+"└─ ", body+"   ", depth+1)
+		} else {
+			f.layer(child, body+"├─ ", body+"│  ", depth+1)
+		}
+	}
+}
+
+// trim applies TrimPrefixes to a file path.
+func (f *formatter) trim(file string) string {
+	for _, prefix := range f.opts.TrimPrefixes {
+		if strings.HasPrefix(file, prefix) {
+			return strings.TrimPrefix(file[len(prefix):], "/")
+		}
+	}
+	return file
+}
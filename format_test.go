@@ -0,0 +1,81 @@
+package e_test
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whynot00/e"
+)
+
+func TestFormat_Chain(t *testing.T) {
+	err := fmt.Errorf("handler: %w", e.WrapWithFields(
+		e.WrapWithMessage(errors.New("no rows"), "loading user"),
+		e.Field("user_id", 42),
+	))
+
+	out := e.Format(err, nil)
+	lines := strings.Split(out, "\n")
+
+	assert.Equal(t, "*fmt.wrapError: handler: no rows", lines[0])
+	assert.Equal(t, "└─ *e.ErrorWrapper: no rows", lines[1])
+	assert.Regexp(t, `^       at .*format_test\.go:\d+ TestFormat_Chain$`, lines[2])
+	assert.Equal(t, "            user_id = 42", lines[3])
+	assert.Regexp(t, `^       at .*format_test\.go:\d+ TestFormat_Chain — loading user$`, lines[4])
+	assert.Equal(t, "   └─ *errors.errorString: no rows", lines[5])
+	assert.Len(t, lines, 6)
+}
+
+func TestFormat_JoinTree(t *testing.T) {
+	err := errors.Join(errors.New("first"), e.Wrap(errors.New("second")))
+
+	out := e.Format(err, nil)
+
+	assert.Contains(t, out, "├─ *errors.errorString: first\n")
+	assert.Contains(t, out, "└─ *e.ErrorWrapper: second\n")
+	assert.Contains(t, out, "   └─ *errors.errorString: second")
+}
+
+func TestFormat_MaxDepth(t *testing.T) {
+	err := fmt.Errorf("a: %w", fmt.Errorf("b: %w", errors.New("c")))
+
+	out := e.Format(err, &e.FormatOpts{MaxDepth: 2})
+
+	assert.Contains(t, out, "b: c")
+	assert.NotContains(t, out, "*errors.errorString")
+	assert.Contains(t, out, "max depth reached")
+}
+
+func TestFormat_TrimPrefixes(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	err := e.Wrap(errors.New("boom"))
+
+	out := e.Format(err, &e.FormatOpts{TrimPrefixes: []string{"/nonexistent", filepath.Dir(file)}})
+
+	assert.Contains(t, out, " at format_test.go:")
+}
+
+func TestFormat_Color(t *testing.T) {
+	out := e.Format(e.Wrap(errors.New("boom")), &e.FormatOpts{Color: true})
+
+	assert.Contains(t, out, "\033[")
+	assert.NotContains(t, e.Format(e.Wrap(errors.New("boom")), nil), "\033[")
+}
+
+func TestFormat_Nil(t *testing.T) {
+	assert.Equal(t, "<nil>", e.Format(nil, nil))
+}
+
+func TestErrorWrapper_FormatVerbs(t *testing.T) {
+	err := e.WrapWithMessage(errors.New("boom"), "context")
+
+	assert.Equal(t, "boom", fmt.Sprintf("%v", err))
+	assert.Equal(t, "boom", fmt.Sprintf("%s", err))
+	assert.Equal(t, `"boom"`, fmt.Sprintf("%q", err))
+	assert.Equal(t, e.Format(err, nil), fmt.Sprintf("%+v", err))
+	assert.Equal(t, "outer: boom", fmt.Errorf("outer: %w", err).Error())
+}
@@ -0,0 +1,119 @@
+package e
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// freezeError returns a copy of err that later wraps cannot change.
+//
+// Wrap and friends modify an existing *ErrorWrapper in place, so an error
+// handed to a background goroutine (the Reporter, the Recorder) would race
+// with the caller wrapping it further up the stack. Every *ErrorWrapper in
+// the chain is cloned, and the layers above them are replaced by frozen
+// copies that keep their text and type and still match with errors.Is and
+// errors.As. Chains without an *ErrorWrapper are returned as is.
+func freezeError(err error) error {
+	if !hasWrapper(err) {
+		return err
+	}
+
+	switch u := err.(type) {
+	case *ErrorWrapper:
+		return u.clone()
+	case interface{ Unwrap() []error }:
+		children := u.Unwrap()
+		frozen := make([]error, 0, len(children))
+		for _, child := range children {
+			if child != nil {
+				frozen = append(frozen, freezeError(child))
+			}
+		}
+		return &frozenJoin{frozenLayer{orig: err, msg: err.Error()}, frozen}
+	case interface{ Unwrap() error }:
+		return &frozenError{frozenLayer{orig: err, msg: err.Error()}, freezeError(u.Unwrap())}
+	}
+	return err
+}
+
+// hasWrapper reports whether the chain of err contains an *ErrorWrapper.
+func hasWrapper(err error) bool {
+	if err == nil {
+		return false
+	}
+	if _, ok := err.(*ErrorWrapper); ok {
+		return true
+	}
+	return slices.ContainsFunc(unwrapAll(err), hasWrapper)
+}
+
+// clone returns a copy of e whose frames and chain are not shared with e.
+// Frame field slices are never modified after creation and are shared.
+func (e *ErrorWrapper) clone() *ErrorWrapper {
+	cp := *e
+	cp.err = freezeError(e.err)
+	cp.frames = slices.Clone(e.frames)
+	return &cp
+}
+
+// frozenLayer holds the text and identity of a frozen layer of a chain.
+type frozenLayer struct {
+	orig error
+	msg  string
+}
+
+func (l *frozenLayer) Error() string { return l.msg }
+
+// Is matches the original layer and delegates to its own Is method.
+func (l *frozenLayer) Is(target error) bool {
+	if reflect.TypeOf(target).Comparable() && l.orig == target {
+		return true
+	}
+	if x, ok := l.orig.(interface{ Is(error) bool }); ok {
+		return x.Is(target)
+	}
+	return false
+}
+
+// As assigns the original layer to target if it has a matching type, and
+// otherwise delegates to its own As method.
+func (l *frozenLayer) As(target any) bool {
+	val := reflect.ValueOf(target).Elem()
+	if reflect.TypeOf(l.orig).AssignableTo(val.Type()) {
+		val.Set(reflect.ValueOf(l.orig))
+		return true
+	}
+	if x, ok := l.orig.(interface{ As(any) bool }); ok {
+		return x.As(target)
+	}
+	return false
+}
+
+// typeName returns the type of the original layer, for reports.
+func (l *frozenLayer) typeName() string { return fmt.Sprintf("%T", l.orig) }
+
+// frozenError is a frozen layer with a single wrapped error.
+type frozenError struct {
+	frozenLayer
+	next error
+}
+
+func (f *frozenError) Unwrap() error { return f.next }
+
+// frozenJoin is a frozen layer wrapping several errors.
+type frozenJoin struct {
+	frozenLayer
+	next []error
+}
+
+func (f *frozenJoin) Unwrap() []error { return f.next }
+
+// errorType returns the Go type of err as shown in reports,
+// looking through frozen layers.
+func errorType(err error) string {
+	if l, ok := err.(interface{ typeName() string }); ok {
+		return l.typeName()
+	}
+	return fmt.Sprintf("%T", err)
+}
@@ -0,0 +1,70 @@
+package e
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+)
+
+// MessageMode selects the text returned by ErrorWrapper.Error.
+type MessageMode int32
+
+const (
+	// MessageDefault defers to the global mode set with SetMessageMode.
+	MessageDefault MessageMode = iota
+
+	// MessageBase returns only the text of the underlying error.
+	// It is the global mode unless changed.
+	MessageBase
+
+	// MessageChain prepends the messages attached with WrapWithMessage,
+	// outermost first, the way fmt.Errorf chains do: "second: first: root".
+	MessageChain
+)
+
+// globalMessageMode holds the mode used by errors whose own mode is MessageDefault.
+var globalMessageMode atomic.Int32
+
+// SetMessageMode sets the message mode used by every ErrorWrapper that has
+// no mode of its own. MessageDefault resets it to MessageBase.
+func SetMessageMode(mode MessageMode) {
+	globalMessageMode.Store(int32(mode))
+}
+
+// WithMessageMode sets the message mode of a single error, overriding the
+// global mode. If err is not wrapped yet, it is wrapped with the current call site.
+func WithMessageMode(err error, mode MessageMode) error {
+	if err == nil {
+		return nil
+	}
+
+	var ew *ErrorWrapper
+	if !errors.As(err, &ew) {
+		ew = wrapWithSkip(err, 2, "", nil)
+		err = ew
+	}
+	ew.mode = mode
+	return err
+}
+
+// messageMode resolves the effective mode of e.
+func (e *ErrorWrapper) messageMode() MessageMode {
+	if e.mode != MessageDefault {
+		return e.mode
+	}
+	return MessageMode(globalMessageMode.Load())
+}
+
+// chainText joins the frame messages (outermost first) and the underlying
+// error text with ": ".
+func (e *ErrorWrapper) chainText() string {
+	var sb strings.Builder
+	for _, f := range e.frames {
+		if f.message != "" {
+			sb.WriteString(f.message)
+			sb.WriteString(": ")
+		}
+	}
+	sb.WriteString(e.err.Error())
+	return sb.String()
+}
@@ -0,0 +1,68 @@
+package e_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+)
+
+func chainedError() error {
+	err := e.WrapWithMessage(errors.New("root"), "first")
+	err = e.Wrap(err)
+	return e.WrapWithMessage(err, "second")
+}
+
+func TestMessageMode_DefaultIsBase(t *testing.T) {
+	assert.Equal(t, "root", chainedError().Error())
+}
+
+func TestMessageMode_Global(t *testing.T) {
+	e.SetMessageMode(e.MessageChain)
+	t.Cleanup(func() { e.SetMessageMode(e.MessageDefault) })
+
+	assert.Equal(t, "second: first: root", chainedError().Error())
+}
+
+func TestMessageMode_PerError(t *testing.T) {
+	err := e.WithMessageMode(chainedError(), e.MessageChain)
+	assert.Equal(t, "second: first: root", err.Error())
+
+	// A per-error mode wins over the global one.
+	e.SetMessageMode(e.MessageChain)
+	t.Cleanup(func() { e.SetMessageMode(e.MessageDefault) })
+
+	base := e.WithMessageMode(chainedError(), e.MessageBase)
+	assert.Equal(t, "root", base.Error())
+}
+
+func TestWithMessageMode_WrapsPlainError(t *testing.T) {
+	err := e.WithMessageMode(errors.New("plain"), e.MessageChain)
+
+	var ew *e.ErrorWrapper
+	require.ErrorAs(t, err, &ew)
+	assert.Len(t, ew.StackTrace(), 1)
+	assert.Nil(t, e.WithMessageMode(nil, e.MessageChain))
+}
+
+func TestMessageMode_SeparateKeys(t *testing.T) {
+	err := chainedError()
+
+	values := map[string]string{}
+	for _, a := range e.SlogGroup(err).Value.Group() {
+		values[a.Key] = a.Value.String()
+	}
+	assert.Equal(t, "root", values["error_text"])
+	assert.Equal(t, "second: first: root", values["error_chain"])
+
+	data, errJSON := json.Marshal(err)
+	require.NoError(t, errJSON)
+
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, "root", out["error"])
+	assert.Equal(t, "second: first: root", out["error_chain"])
+}
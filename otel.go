@@ -0,0 +1,96 @@
+package e
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RecordSpan records err on the span stored in ctx, following the
+// OpenTelemetry semantic conventions for exceptions.
+//
+// It sets the span status to Error, adds an "exception" event with
+// exception.type, exception.message and exception.stacktrace (built from the
+// captured frames), and attaches the error's custom fields as span attributes.
+// Nil errors and non-recording spans are ignored.
+func RecordSpan(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	baseErr := err
+	var ew *ErrorWrapper
+	if errors.As(err, &ew) {
+		baseErr = ew.err
+	}
+
+	msg := redactText(err.Error())
+	span.SetStatus(codes.Error, msg)
+
+	event := []attribute.KeyValue{
+		attribute.String("exception.type", errorType(baseErr)),
+		attribute.String("exception.message", msg),
+	}
+	if ew != nil && len(ew.frames) > 0 {
+		event = append(event, attribute.String("exception.stacktrace", otelStacktrace(ew.frames)))
+	}
+	span.AddEvent("exception", trace.WithAttributes(event...))
+
+	if ew != nil {
+		fields := ew.renderFields(redaction())
+		attrs := make([]attribute.KeyValue, 0, len(fields))
+		for _, kv := range fields {
+			attrs = append(attrs, otelAttribute(kv.Key, kv.any()))
+		}
+		span.SetAttributes(attrs...)
+	}
+}
+
+// otelStacktrace renders frames in a Go traceback-like text form:
+// one "function" line followed by an indented "file:line" line per frame.
+func otelStacktrace(frames []frame) string {
+	var sb strings.Builder
+	for i, f := range frames {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		fmt.Fprintf(&sb, "%s\n\t%s:%d", f.funcName, f.path(), f.line)
+		if f.message != "" {
+			sb.WriteString(" ")
+			sb.WriteString(redactText(f.message))
+		}
+	}
+	return sb.String()
+}
+
+// otelAttribute converts a field value into the closest attribute type.
+func otelAttribute(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case []string:
+		return attribute.StringSlice(key, v)
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,91 @@
+package e_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestTracer(t *testing.T) (*tracetest.InMemoryExporter, func(ctx context.Context) (context.Context, func())) {
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	t.Cleanup(func() { tp.Shutdown(context.Background()) })
+
+	return exp, func(ctx context.Context) (context.Context, func()) {
+		ctx, span := tp.Tracer("e_test").Start(ctx, "op")
+		return ctx, func() { span.End() }
+	}
+}
+
+func attrMap(attrs []attribute.KeyValue) map[string]attribute.Value {
+	m := make(map[string]attribute.Value, len(attrs))
+	for _, a := range attrs {
+		m[string(a.Key)] = a.Value
+	}
+	return m
+}
+
+func TestRecordSpan(t *testing.T) {
+	exp, start := newTestTracer(t)
+
+	ctx, end := start(context.Background())
+	err := e.WrapWithFields(
+		e.WrapWithMessage(errors.New("no rows"), "loading user"),
+		e.Field("user_id", 42),
+		e.Field("tenant", "foo"),
+	)
+	e.RecordSpan(ctx, err)
+	end()
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+
+	assert.Equal(t, codes.Error, span.Status.Code)
+	assert.Equal(t, "no rows", span.Status.Description)
+
+	require.Len(t, span.Events, 1)
+	assert.Equal(t, "exception", span.Events[0].Name)
+
+	event := attrMap(span.Events[0].Attributes)
+	assert.Equal(t, "*errors.errorString", event["exception.type"].AsString())
+	assert.Equal(t, "no rows", event["exception.message"].AsString())
+
+	stack := event["exception.stacktrace"].AsString()
+	assert.Contains(t, stack, "TestRecordSpan\n\t")
+	assert.Contains(t, stack, "otel_test.go:")
+	assert.Contains(t, stack, "loading user")
+	assert.Equal(t, 2, strings.Count(stack, "\n\t"))
+
+	fields := attrMap(span.Attributes)
+	assert.Equal(t, int64(42), fields["user_id"].AsInt64())
+	assert.Equal(t, "foo", fields["tenant"].AsString())
+}
+
+func TestRecordSpan_PlainError(t *testing.T) {
+	exp, start := newTestTracer(t)
+
+	ctx, end := start(context.Background())
+	e.RecordSpan(ctx, errors.New("plain"))
+	end()
+
+	event := attrMap(exp.GetSpans()[0].Events[0].Attributes)
+	assert.Equal(t, "plain", event["exception.message"].AsString())
+	assert.NotContains(t, event, "exception.stacktrace")
+}
+
+func TestRecordSpan_NoSpan(t *testing.T) {
+	assert.NotPanics(t, func() {
+		e.RecordSpan(context.Background(), errors.New("x"))
+		e.RecordSpan(context.Background(), nil)
+	})
+}
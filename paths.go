@@ -0,0 +1,170 @@
+package e
+
+import (
+	"go/build"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// PathOpts controls how source file paths of frames are rendered by
+// StackTrace, SlogGroup, MarshalJSON and the other outputs of the package.
+//
+// Trimming is applied at render time, so the captured frames keep the
+// original absolute paths.
+type PathOpts struct {
+	// ModuleRelative renders files of the main module (as reported by
+	// debug.ReadBuildInfo) relative to the module root, e.g. "internal/db/db.go".
+	ModuleRelative bool
+
+	// GOROOT renders standard library files relative to GOROOT/src, e.g. "net/http/server.go".
+	GOROOT bool
+
+	// ModCache renders files from the module cache as "module@version/path".
+	ModCache bool
+
+	// Prefixes are removed from the beginning of paths that no other rule
+	// applies to. The first matching prefix wins.
+	Prefixes []string
+}
+
+// pathOpts holds the options set with SetPathOpts; nil disables trimming.
+var pathOpts atomic.Pointer[PathOpts]
+
+// SetPathOpts sets how file paths are rendered. A nil opts restores the
+// default behavior of rendering absolute paths.
+func SetPathOpts(opts *PathOpts) {
+	if opts == nil {
+		pathOpts.Store(nil)
+		return
+	}
+	cp := *opts
+	cp.Prefixes = append([]string(nil), opts.Prefixes...)
+	pathOpts.Store(&cp)
+}
+
+// path returns the file of f rendered according to SetPathOpts.
+func (f frame) path() string {
+	opts := pathOpts.Load()
+	if opts == nil {
+		return f.file
+	}
+	return trimPath(f.file, f.function, opts)
+}
+
+// trimPath applies opts to file, which belongs to the fully qualified function fn.
+func trimPath(file, fn string, opts *PathOpts) string {
+	slashed := filepath.ToSlash(file)
+
+	if opts.ModuleRelative {
+		if rel, ok := moduleRelativePath(slashed, fn); ok {
+			return rel
+		}
+	}
+
+	if opts.ModCache {
+		if rel, ok := modCachePath(slashed); ok {
+			return rel
+		}
+	}
+
+	if opts.GOROOT && build.Default.GOROOT != "" {
+		prefix := strings.TrimSuffix(filepath.ToSlash(build.Default.GOROOT), "/") + "/src/"
+		if strings.HasPrefix(slashed, prefix) {
+			return slashed[len(prefix):]
+		}
+	}
+
+	for _, prefix := range opts.Prefixes {
+		if prefix != "" && strings.HasPrefix(file, prefix) {
+			return strings.TrimPrefix(file[len(prefix):], "/")
+		}
+	}
+
+	return file
+}
+
+// mainModule describes the main module of the running binary.
+var mainModule = sync.OnceValues(func() (path, mainPkg string) {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", ""
+	}
+	return bi.Main.Path, bi.Path
+})
+
+// moduleRelativePath returns file relative to the main module root if fn
+// belongs to a package of the main module.
+//
+// The package directory is derived from the import path of fn, so this
+// works regardless of where the module was checked out on the build host.
+func moduleRelativePath(file, fn string) (string, bool) {
+	modPath, mainPkg := mainModule()
+	if modPath == "" {
+		return "", false
+	}
+
+	pkg := funcPackage(fn)
+	if pkg == "main" {
+		pkg = mainPkg
+	}
+	// External test packages are named after the package they test.
+	pkg = strings.TrimSuffix(pkg, "_test")
+
+	if pkg != modPath && !strings.HasPrefix(pkg, modPath+"/") {
+		return "", false
+	}
+
+	relDir := strings.TrimPrefix(pkg[len(modPath):], "/")
+	dir, base := filepath.ToSlash(filepath.Dir(file)), filepath.Base(file)
+
+	if relDir == "" {
+		return base, true
+	}
+	if !strings.HasSuffix(dir, "/"+relDir) && dir != relDir {
+		return "", false
+	}
+	return relDir + "/" + base, true
+}
+
+// funcPackage returns the import path of the package that defines the fully
+// qualified function fn, e.g. "github.com/foo/bar" for "github.com/foo/bar.(*T).M".
+func funcPackage(fn string) string {
+	// Generic instantiations may contain further paths inside brackets.
+	if i := strings.IndexByte(fn, '['); i != -1 {
+		fn = fn[:i]
+	}
+
+	slash := strings.LastIndex(fn, "/")
+	if dot := strings.IndexByte(fn[slash+1:], '.'); dot != -1 {
+		return fn[:slash+1+dot]
+	}
+	return fn
+}
+
+// modCachePath rewrites a path inside the module cache as "module@version/path".
+func modCachePath(file string) (string, bool) {
+	roots := []string{os.Getenv("GOMODCACHE")}
+	if build.Default.GOPATH != "" {
+		roots = append(roots, filepath.Join(filepath.SplitList(build.Default.GOPATH)[0], "pkg", "mod"))
+	}
+
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		prefix := strings.TrimSuffix(filepath.ToSlash(root), "/") + "/"
+		if strings.HasPrefix(file, prefix) {
+			return file[len(prefix):], true
+		}
+	}
+
+	// Binaries built elsewhere: fall back to the conventional layout.
+	if i := strings.LastIndex(file, "/pkg/mod/"); i != -1 {
+		return file[i+len("/pkg/mod/"):], true
+	}
+	return "", false
+}
@@ -0,0 +1,113 @@
+package e
+
+import (
+	"encoding/json"
+	"errors"
+	"go/build"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFuncPackage(t *testing.T) {
+	tests := []struct {
+		fn   string
+		want string
+	}{
+		{"main.main", "main"},
+		{"github.com/foo/bar.Func", "github.com/foo/bar"},
+		{"github.com/foo/bar.(*T).Method", "github.com/foo/bar"},
+		{"github.com/foo/bar/v2.Func.func1", "github.com/foo/bar/v2"},
+		{"github.com/foo/bar.Map[go.shape.*github.com/x/y.T]", "github.com/foo/bar"},
+	}
+
+	for _, tt := range tests {
+		if got := funcPackage(tt.fn); got != tt.want {
+			t.Errorf("funcPackage(%q) = %q; want %q", tt.fn, got, tt.want)
+		}
+	}
+}
+
+func TestTrimPath(t *testing.T) {
+	all := &PathOpts{ModuleRelative: true, GOROOT: true, ModCache: true, Prefixes: []string{"/srv/"}}
+	goroot := filepath.ToSlash(build.Default.GOROOT)
+
+	tests := []struct {
+		name string
+		file string
+		fn   string
+		want string
+	}{
+		{"module root", "/home/ci/work/e/errors.go", "github.com/whynot00/e.Wrap", "errors.go"},
+		{"module subpackage", "/build/src/debugerrors/x.go", "github.com/whynot00/e/debugerrors.init", "debugerrors/x.go"},
+		{"external test package", "/tmp/e/errors_test.go", "github.com/whynot00/e_test.TestWrap", "errors_test.go"},
+		{"module cache", "/home/ci/go/pkg/mod/github.com/foo/bar@v1.2.3/x/y.go", "github.com/foo/bar/x.F", "github.com/foo/bar@v1.2.3/x/y.go"},
+		{"goroot", goroot + "/src/net/http/server.go", "net/http.(*conn).serve", "net/http/server.go"},
+		{"prefix", "/srv/other/main.go", "other.F", "other/main.go"},
+		{"untouched", "/opt/x.go", "x.F", "/opt/x.go"},
+	}
+
+	for _, tt := range tests {
+		if got := trimPath(tt.file, tt.fn, all); got != tt.want {
+			t.Errorf("%s: trimPath(%q) = %q; want %q", tt.name, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestTrimPath_ModuleDirMismatch(t *testing.T) {
+	// The directory does not end with the package's relative path: keep the file as is.
+	got := trimPath("/gen/x.go", "github.com/whynot00/e/debugerrors.F", &PathOpts{ModuleRelative: true})
+	if got != "/gen/x.go" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestSetPathOpts_AppliedToOutputs(t *testing.T) {
+	SetPathOpts(&PathOpts{ModuleRelative: true})
+	t.Cleanup(func() { SetPathOpts(nil) })
+
+	err := Wrap(errors.New("boom"))
+
+	if file := err.(*ErrorWrapper).StackTrace()[0].file; file != "paths_test.go" {
+		t.Errorf("StackTrace file = %q", file)
+	}
+
+	data, _ := json.Marshal(err)
+	if !strings.Contains(string(data), `"file":"paths_test.go"`) {
+		t.Errorf("MarshalJSON not trimmed: %s", data)
+	}
+
+	for _, a := range SlogGroup(err).Value.Group() {
+		if a.Key == "stack_trace" {
+			if file := a.Value.Any().([]map[string]any)[0]["file"]; file != "paths_test.go" {
+				t.Errorf("SlogGroup file = %v", file)
+			}
+		}
+	}
+
+	// The captured frame keeps the absolute path.
+	if !filepath.IsAbs(err.(*ErrorWrapper).frames[0].file) {
+		t.Error("captured frame must keep the absolute path")
+	}
+}
+
+func TestSourceRepo(t *testing.T) {
+	tests := []struct {
+		modPath   string
+		repo, dir string
+	}{
+		{"github.com/foo/bar", "github.com/foo/bar", ""},
+		{"github.com/foo/bar/v2", "github.com/foo/bar", ""},
+		{"github.com/foo/mono/tools", "github.com/foo/mono", "tools"},
+		{"github.com/foo/mono/tools/v3", "github.com/foo/mono", "tools"},
+		{"gitlab.com/group/sub/proj/v2", "gitlab.com/group/sub/proj", ""},
+		{"example.com/v1", "example.com/v1", ""},
+		{"example.com/lib/v0", "example.com/lib/v0", ""},
+	}
+	for _, tt := range tests {
+		repo, dir := sourceRepo(tt.modPath)
+		if repo != tt.repo || dir != tt.dir {
+			t.Errorf("sourceRepo(%q) = %q, %q; want %q, %q", tt.modPath, repo, dir, tt.repo, tt.dir)
+		}
+	}
+}
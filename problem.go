@@ -0,0 +1,85 @@
+package e
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ProblemContentType is the media type of the bodies written by WriteProblem.
+const ProblemContentType = "application/problem+json"
+
+// httpStatuses maps codes to HTTP statuses.
+var httpStatuses = map[Code]int{
+	Unknown:            http.StatusInternalServerError,
+	InvalidArgument:    http.StatusBadRequest,
+	NotFound:           http.StatusNotFound,
+	AlreadyExists:      http.StatusConflict,
+	PermissionDenied:   http.StatusForbidden,
+	Unauthenticated:    http.StatusUnauthorized,
+	FailedPrecondition: http.StatusPreconditionFailed,
+	Conflict:           http.StatusConflict,
+	ResourceExhausted:  http.StatusTooManyRequests,
+	Canceled:           499, // Client Closed Request, as used by nginx and gRPC gateways.
+	DeadlineExceeded:   http.StatusGatewayTimeout,
+	Unavailable:        http.StatusServiceUnavailable,
+	Unimplemented:      http.StatusNotImplemented,
+	Internal:           http.StatusInternalServerError,
+}
+
+// HTTPStatus returns the HTTP status for the code of err (see CodeOf).
+// Errors without a known code map to 500 Internal Server Error.
+func HTTPStatus(err error) int {
+	if status, ok := httpStatuses[CodeOf(err)]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// problem is an RFC 9457 problem details object.
+type problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Code   Code   `json:"code,omitempty"`
+}
+
+// WriteProblem responds to r with err as RFC 9457 problem details: the
+// status from HTTPStatus, the code of err and, as detail, its public message
+// localized for the Accept-Language of r (see Localize). r may be nil.
+//
+// The text of err, its frames and its fields are never written: log or
+// report the error separately.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	status := HTTPStatus(err)
+
+	var lang string
+	if r != nil {
+		lang = acceptLanguage(r.Header.Get("Accept-Language"))
+	}
+
+	body, _ := json.Marshal(problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: Localize(err, lang),
+		Code:   CodeOf(err),
+	})
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// acceptLanguage returns the first language tag of an Accept-Language
+// header, ignoring quality values, or "" if there is none.
+func acceptLanguage(header string) string {
+	first, _, _ := strings.Cut(header, ",")
+	tag, _, _ := strings.Cut(first, ";")
+	if tag = strings.TrimSpace(tag); tag == "*" {
+		return ""
+	}
+	return tag
+}
@@ -0,0 +1,71 @@
+package e_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	assert.Equal(t, http.StatusInternalServerError, e.HTTPStatus(errors.New("boom")))
+	assert.Equal(t, http.StatusNotFound, e.HTTPStatus(e.WithCode(errors.New("boom"), e.NotFound)))
+	assert.Equal(t, http.StatusInternalServerError, e.HTTPStatus(e.WithCode(errors.New("boom"), "custom")))
+}
+
+func TestWriteProblem(t *testing.T) {
+	base := errors.New(`pq: duplicate key value violates unique constraint "users_email_key"`)
+	err := e.WithPublicMessage(e.WithCode(base, e.AlreadyExists), "email already registered")
+
+	rec := httptest.NewRecorder()
+	e.WriteProblem(rec, httptest.NewRequest(http.MethodPost, "/signup", nil), err)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, e.ProblemContentType, rec.Header().Get("Content-Type"))
+	assert.NotContains(t, rec.Body.String(), "pq:")
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, map[string]any{
+		"type":   "about:blank",
+		"title":  "Conflict",
+		"status": float64(http.StatusConflict),
+		"detail": "email already registered",
+		"code":   "already_exists",
+	}, body)
+}
+
+func TestWriteProblem_Localized(t *testing.T) {
+	c := e.NewCatalog("en")
+	c.Add("de", "user.not_found", "Benutzer {user_id} nicht gefunden")
+	e.SetDefaultCatalog(c)
+	t.Cleanup(func() { e.SetDefaultCatalog(nil) })
+
+	errUserNotFound := e.Define("user.not_found", e.NotFound, "user {user_id} not found")
+	err := errUserNotFound.New(e.Field("user_id", 7))
+
+	req := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+	req.Header.Set("Accept-Language", "de-DE;q=0.9, en;q=0.8")
+
+	rec := httptest.NewRecorder()
+	e.WriteProblem(rec, req, err)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, float64(http.StatusNotFound), body["status"])
+	assert.Equal(t, "Benutzer 7 nicht gefunden", body["detail"])
+}
+
+func TestWriteProblem_NoPublicMessage(t *testing.T) {
+	rec := httptest.NewRecorder()
+	e.WriteProblem(rec, nil, errors.New("secret internals"))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "secret")
+	assert.NotContains(t, rec.Body.String(), "detail")
+}
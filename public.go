@@ -0,0 +1,40 @@
+package e
+
+import "errors"
+
+// WithPublicMessage attaches msg as the message that is safe to show to the
+// users of an API, keeping Error, frames and fields for internal use.
+// If err is not wrapped yet, it is wrapped with the current call site.
+//
+// Calling it again further up the call stack replaces the message, so the
+// outermost public message wins.
+func WithPublicMessage(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+
+	var ew *ErrorWrapper
+	if !errors.As(err, &ew) {
+		ew = wrapWithSkip(err, 2, "", nil)
+		err = ew
+	}
+	ew.public = msg
+	return err
+}
+
+// PublicMessage returns the public message set with WithPublicMessage
+// anywhere in the chain of err, including the branches of joined errors,
+// or fallback if there is none. It never returns the text of err itself.
+func PublicMessage(err error, fallback string) string {
+	if msg, ok := publicMessage(err); ok {
+		return msg
+	}
+	return fallback
+}
+
+func publicMessage(err error) (string, bool) {
+	if ew, ok := findWrapper(err, func(ew *ErrorWrapper) bool { return ew.public != "" }); ok {
+		return ew.public, true
+	}
+	return "", false
+}
@@ -0,0 +1,37 @@
+package e_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whynot00/e"
+)
+
+func TestPublicMessage(t *testing.T) {
+	base := errors.New(`pq: duplicate key value violates unique constraint "users_email_key"`)
+
+	assert.Equal(t, "internal error", e.PublicMessage(base, "internal error"))
+	assert.Equal(t, "internal error", e.PublicMessage(nil, "internal error"))
+
+	err := e.WithPublicMessage(base, "email already registered")
+	assert.Equal(t, "email already registered", e.PublicMessage(err, "internal error"))
+	assert.Equal(t, base.Error(), err.Error(), "Error must keep the internal text")
+
+	wrapped := fmt.Errorf("signup: %w", e.Wrap(err))
+	assert.Equal(t, "email already registered", e.PublicMessage(wrapped, "internal error"))
+}
+
+func TestPublicMessage_OutermostWins(t *testing.T) {
+	err := e.WithPublicMessage(e.Wrap(errors.New("boom")), "inner")
+	err = e.WithPublicMessage(e.Wrap(err), "outer")
+
+	assert.Equal(t, "outer", e.PublicMessage(err, ""))
+}
+
+func TestPublicMessage_Joined(t *testing.T) {
+	err := errors.Join(errors.New("a"), e.WithPublicMessage(errors.New("b"), "try again later"))
+
+	assert.Equal(t, "try again later", e.PublicMessage(err, ""))
+}
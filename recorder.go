@@ -0,0 +1,142 @@
+package e
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultRecorderSize is the number of distinct errors kept by DefaultRecorder.
+const DefaultRecorderSize = 100
+
+// DefaultRecorder is the in-process flight recorder fed by Report.
+var DefaultRecorder = NewRecorder(DefaultRecorderSize)
+
+// Report records err in DefaultRecorder and, if one is set with
+// SetDefaultReporter, enqueues it on the default Reporter.
+// It is safe for concurrent use; nil errors are ignored.
+func Report(err error) {
+	DefaultRecorder.Record(err)
+
+	if r := defaultReporter.Load(); r != nil {
+		r.Report(err)
+	}
+}
+
+// RecordEntry describes a group of recorded errors sharing the same fingerprint.
+type RecordEntry struct {
+	// Fingerprint is the value returned by Fingerprint for every error in the group.
+	Fingerprint string
+
+	// Count is the number of times an error with this fingerprint was recorded.
+	Count int
+
+	// FirstSeen and LastSeen are the times the group was first and last recorded.
+	FirstSeen time.Time
+	LastSeen  time.Time
+
+	// Sample is the most recently recorded error of the group.
+	Sample error
+}
+
+// Recorder is a bounded, concurrency-safe in-memory store of recently reported
+// errors, grouped by Fingerprint.
+//
+// When the recorder is full, recording an error with a new fingerprint evicts
+// the group that was seen least recently.
+type Recorder struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*RecordEntry
+}
+
+// NewRecorder returns a Recorder that keeps at most size distinct errors.
+// A non-positive size falls back to DefaultRecorderSize.
+func NewRecorder(size int) *Recorder {
+	if size <= 0 {
+		size = DefaultRecorderSize
+	}
+	return &Recorder{
+		size:    size,
+		entries: make(map[string]*RecordEntry, size),
+	}
+}
+
+// Record adds err to the recorder. Nil errors are ignored.
+func (r *Recorder) Record(err error) {
+	if err == nil {
+		return
+	}
+
+	fp := Fingerprint(err)
+	// The sample is read later by Entries and the debug handler; keep a copy
+	// that the caller's further wraps cannot modify.
+	sample := freezeError(err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+
+	if entry, ok := r.entries[fp]; ok {
+		entry.Count++
+		entry.LastSeen = now
+		entry.Sample = sample
+		return
+	}
+
+	if len(r.entries) >= r.size {
+		r.evictOldest()
+	}
+
+	r.entries[fp] = &RecordEntry{
+		Fingerprint: fp,
+		Count:       1,
+		FirstSeen:   now,
+		LastSeen:    now,
+		Sample:      sample,
+	}
+}
+
+// evictOldest removes the group with the oldest LastSeen. The caller must hold r.mu.
+func (r *Recorder) evictOldest() {
+	var oldest *RecordEntry
+	for _, entry := range r.entries {
+		if oldest == nil || entry.LastSeen.Before(oldest.LastSeen) {
+			oldest = entry
+		}
+	}
+	if oldest != nil {
+		delete(r.entries, oldest.Fingerprint)
+	}
+}
+
+// Entries returns a snapshot of the recorded groups, most recently seen first.
+func (r *Recorder) Entries() []RecordEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]RecordEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		out = append(out, *entry)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].LastSeen.After(out[j].LastSeen)
+	})
+	return out
+}
+
+// Len returns the number of distinct errors currently recorded.
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// Reset removes all recorded errors.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.entries)
+}
@@ -0,0 +1,134 @@
+package e_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+)
+
+func TestRecorder_GroupsByFingerprint(t *testing.T) {
+	rec := e.NewRecorder(10)
+
+	for i := 0; i < 3; i++ {
+		rec.Record(loadUser(i))
+	}
+	rec.Record(loadOrder(1))
+
+	entries := rec.Entries()
+	require.Len(t, entries, 2)
+
+	// Most recently seen first.
+	assert.Equal(t, 1, entries[0].Count)
+	assert.Equal(t, 3, entries[1].Count)
+	assert.Equal(t, e.Fingerprint(loadUser(0)), entries[1].Fingerprint)
+	assert.EqualError(t, entries[1].Sample, "user 2 not found")
+	assert.False(t, entries[1].FirstSeen.After(entries[1].LastSeen))
+}
+
+func TestRecorder_SeparatesUnwrappedErrors(t *testing.T) {
+	rec := e.NewRecorder(10)
+
+	rec.Record(errors.New("a"))
+	rec.Record(errors.New("b"))
+	rec.Record(errors.New("a"))
+
+	entries := rec.Entries()
+	require.Len(t, entries, 2)
+	assert.EqualError(t, entries[0].Sample, "a")
+	assert.Equal(t, 2, entries[0].Count)
+	assert.EqualError(t, entries[1].Sample, "b")
+}
+
+func TestRecorder_EvictsLeastRecentlySeen(t *testing.T) {
+	rec := e.NewRecorder(2)
+
+	rec.Record(loadUser(1))
+	rec.Record(loadOrder(1))
+	rec.Record(loadUser(2))
+	rec.Record(e.Wrap(errors.New("third")))
+
+	require.Equal(t, 2, rec.Len())
+	for _, entry := range rec.Entries() {
+		assert.NotEqual(t, e.Fingerprint(loadOrder(1)), entry.Fingerprint)
+	}
+}
+
+func TestRecorder_IgnoresNil(t *testing.T) {
+	rec := e.NewRecorder(2)
+	rec.Record(nil)
+
+	assert.Zero(t, rec.Len())
+}
+
+func TestRecorder_Reset(t *testing.T) {
+	rec := e.NewRecorder(2)
+	rec.Record(loadUser(1))
+	rec.Reset()
+
+	assert.Empty(t, rec.Entries())
+}
+
+func TestRecorder_Concurrent(t *testing.T) {
+	rec := e.NewRecorder(5)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rec.Record(loadUser(i))
+		}(i)
+	}
+	wg.Wait()
+
+	entries := rec.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, 50, entries[0].Count)
+}
+
+func TestRecover_Report(t *testing.T) {
+	e.DefaultRecorder.Reset()
+	t.Cleanup(e.DefaultRecorder.Reset)
+
+	func() {
+		defer e.Recover(&e.RecoverOpts{Report: true, RecoverOnly: true}, nil)
+		panic("reported panic")
+	}()
+
+	entries := e.DefaultRecorder.Entries()
+	require.Len(t, entries, 1)
+	assert.EqualError(t, entries[0].Sample, "reported panic")
+}
+
+// Run with -race: the caller keeps wrapping the error after recording it
+// while the sample is rendered.
+func TestRecorder_SnapshotsSample(t *testing.T) {
+	rec := e.NewRecorder(10)
+
+	err := e.WrapWithFields(errors.New("boom"), e.Field("attempt", 1))
+	rec.Record(err)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 100 {
+			_ = e.WrapWithFields(err, e.Field("outer", i))
+		}
+	}()
+	for range 20 {
+		for _, entry := range rec.Entries() {
+			_ = e.Format(entry.Sample, nil)
+		}
+	}
+	wg.Wait()
+
+	sample := rec.Entries()[0].Sample.(*e.ErrorWrapper)
+	assert.Len(t, sample.StackTrace(), 1)
+	_, ok := e.FieldOf(sample, "outer")
+	assert.False(t, ok)
+}
@@ -21,7 +21,13 @@ type RecoverOpts struct {
 
 	// Fatal forces the application to terminate with exit code 1 after recovering the panic.
 	// Useful in CLI tools, workers, or when panic is considered unrecoverable.
+	// Before exiting, the default reporter (see SetDefaultReporter) is flushed.
 	Fatal bool
+
+	// Report passes the recovered error to Report, so it shows up in DefaultRecorder
+	// and is delivered by the default reporter.
+	// It is applied even when RecoverOnly is set.
+	Report bool
 }
 
 // WrapRecovered wraps the recovered panic value `r` into an error with optional stack trace.
@@ -36,8 +42,9 @@ func WrapRecovered(opts *RecoverOpts, r any) error {
 	}
 
 	return &ErrorWrapper{
-		err:    errors.New(message),
-		frames: stack,
+		err:      errors.New(message),
+		frames:   stack,
+		panicked: true,
 	}
 }
 
@@ -52,11 +59,16 @@ func Recover(opts *RecoverOpts, callback func(error)) {
 	if r := recover(); r != nil {
 		err := WrapRecovered(opts, r)
 
+		if opts != nil && opts.Report {
+			Report(err)
+		}
+
 		if opts == nil || !opts.RecoverOnly {
 			callback(err)
 		}
 
 		if opts != nil && opts.Fatal {
+			flushDefaultReporter()
 			os.Exit(1)
 		}
 	}
@@ -75,6 +87,10 @@ func RecoverToChannel(opts *RecoverOpts, errChan chan<- error) {
 	if r := recover(); r != nil {
 		err := WrapRecovered(opts, r)
 
+		if opts != nil && opts.Report {
+			Report(err)
+		}
+
 		if opts == nil || !opts.RecoverOnly {
 			// Use select to avoid panic if channel is full (e.g., buffered without reader)
 			select {
@@ -85,6 +101,7 @@ func RecoverToChannel(opts *RecoverOpts, errChan chan<- error) {
 		}
 
 		if opts != nil && opts.Fatal {
+			flushDefaultReporter()
 			os.Exit(1)
 		}
 	}
@@ -0,0 +1,256 @@
+package e
+
+import (
+	"log/slog"
+	"path"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// Redactor is implemented by field values that hide their own sensitive
+// parts. Outputs render the result of Redact instead of the value itself.
+type Redactor interface {
+	Redact() any
+}
+
+// RenderMode selects whether outputs are redacted.
+type RenderMode int
+
+const (
+	// RenderDefault selects the mode of RedactOpts.Mode. It is the default
+	// of the Mode option of every output; as RedactOpts.Mode it means
+	// RenderPublic.
+	RenderDefault RenderMode = iota
+
+	// RenderPublic applies the redaction rules.
+	RenderPublic
+
+	// RenderInternal disables redaction, for trusted destinations such as
+	// local development consoles.
+	RenderInternal
+)
+
+// DefaultRedactReplacement replaces redacted values and message text.
+const DefaultRedactReplacement = "[REDACTED]"
+
+// DefaultRedactKeys is a starting set of key patterns for RedactOpts.Keys.
+var DefaultRedactKeys = []string{
+	"password", "passwd", "*secret*", "*token*", "authorization", "cookie", "set-cookie", "api_key", "apikey",
+}
+
+// Patterns for common sensitive data in message text, for RedactOpts.Patterns.
+var (
+	EmailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	CardNumberPattern = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+)
+
+// RedactOpts configures redaction of the SlogGroup and LogValue output,
+// MarshalJSON, %+v and Format, the console handler, the debug handler and
+// every exporter (sinks, Sentry, OpenTelemetry).
+//
+// The slog and console handlers, Format, the file and webhook sinks and the
+// Sentry sink have a Mode option of their own, so that, say, a local log
+// can be rendered internally while exports stay public.
+//
+// Redaction is applied at render time: Error, Fields and FieldOf keep
+// returning the original values.
+type RedactOpts struct {
+	// Keys lists field keys whose values are replaced, as path.Match
+	// patterns matched case-insensitively, e.g. "password" or "*token*".
+	// Keys of nested groups, map[string]any and map[string]string values
+	// are matched too.
+	Keys []string
+
+	// Patterns are replaced in error messages, frame messages and string
+	// field values.
+	Patterns []*regexp.Regexp
+
+	// Replacement is the text rendered instead of redacted data.
+	// Default: DefaultRedactReplacement.
+	Replacement string
+
+	// Mode selects public (redacted) or internal (unredacted) rendering
+	// for the outputs whose own Mode is RenderDefault.
+	Mode RenderMode
+}
+
+// redactOpts holds the options set with SetRedaction; nil means no rules
+// besides Redactor values.
+var redactOpts atomic.Pointer[RedactOpts]
+
+// SetRedaction sets the redaction rules. A nil opts removes all rules;
+// values implementing Redactor are still redacted.
+func SetRedaction(opts *RedactOpts) {
+	if opts == nil {
+		redactOpts.Store(nil)
+		return
+	}
+	cp := *opts
+	cp.Keys = append([]string(nil), opts.Keys...)
+	cp.Patterns = append([]*regexp.Regexp(nil), opts.Patterns...)
+	if cp.Replacement == "" {
+		cp.Replacement = DefaultRedactReplacement
+	}
+	redactOpts.Store(&cp)
+}
+
+// maxRedactDepth bounds the recursion into nested maps and slices; deeper
+// values, including those of self-referencing containers, are replaced.
+const maxRedactDepth = 32
+
+// noRedactRules applies only Redactor values.
+var noRedactRules = RedactOpts{Replacement: DefaultRedactReplacement}
+
+// redaction returns the current rules, or nil when output is not redacted.
+func redaction() *RedactOpts {
+	return redactionFor(RenderDefault)
+}
+
+// redactionFor returns the rules of an output rendering in mode, or nil
+// when it is not redacted.
+func redactionFor(mode RenderMode) *RedactOpts {
+	o := redactOpts.Load()
+	if mode == RenderDefault && o != nil {
+		mode = o.Mode
+	}
+	switch {
+	case mode == RenderInternal:
+		return nil
+	case o == nil:
+		return &noRedactRules
+	}
+	return o
+}
+
+// redactText applies the current message patterns to s.
+func redactText(s string) string {
+	return redaction().redactText(s)
+}
+
+// redactFields returns list with the current redaction rules applied.
+func redactFields(list []KeyValue) []KeyValue {
+	return redaction().redactFields(list)
+}
+
+// redactText applies the message patterns of o to s. A nil o leaves s as is.
+func (o *RedactOpts) redactText(s string) string {
+	if o == nil {
+		return s
+	}
+	return o.text(s)
+}
+
+// redactFields returns list with the rules of o applied. A nil o leaves
+// list as is.
+func (o *RedactOpts) redactFields(list []KeyValue) []KeyValue {
+	if o == nil || len(list) == 0 {
+		return list
+	}
+	out := make([]KeyValue, len(list))
+	for i, kv := range list {
+		out[i] = o.field(kv)
+	}
+	return out
+}
+
+func (o *RedactOpts) text(s string) string {
+	for _, re := range o.Patterns {
+		s = re.ReplaceAllString(s, o.Replacement)
+	}
+	return s
+}
+
+func (o *RedactOpts) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range o.Keys {
+		if ok, _ := path.Match(strings.ToLower(pattern), key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *RedactOpts) field(kv KeyValue) KeyValue {
+	if o.matchKey(kv.Key) {
+		return KeyValue{Key: kv.Key, Value: o.Replacement}
+	}
+	if !kv.typed {
+		return KeyValue{Key: kv.Key, Value: o.value(kv.Value, 0)}
+	}
+	kv.val = o.slogValue(kv.val)
+	return kv
+}
+
+// value redacts v, walking the maps and slices of generic values such as
+// decoded JSON. Other containers are rendered as is.
+func (o *RedactOpts) value(v any, depth int) any {
+	switch t := v.(type) {
+	case Redactor:
+		return t.Redact()
+	case string:
+		return o.text(t)
+	case map[string]any:
+		if depth >= maxRedactDepth {
+			return o.Replacement
+		}
+		out := make(map[string]any, len(t))
+		for k, child := range t {
+			if o.matchKey(k) {
+				out[k] = o.Replacement
+			} else {
+				out[k] = o.value(child, depth+1)
+			}
+		}
+		return out
+	case map[string]string:
+		out := make(map[string]string, len(t))
+		for k, child := range t {
+			if o.matchKey(k) {
+				out[k] = o.Replacement
+			} else {
+				out[k] = o.text(child)
+			}
+		}
+		return out
+	case []any:
+		if depth >= maxRedactDepth {
+			return o.Replacement
+		}
+		out := make([]any, len(t))
+		for i, child := range t {
+			out[i] = o.value(child, depth+1)
+		}
+		return out
+	}
+	return v
+}
+
+func (o *RedactOpts) slogValue(v slog.Value) slog.Value {
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.StringValue(o.text(v.String()))
+	case slog.KindAny:
+		return slog.AnyValue(o.value(v.Any(), 0))
+	case slog.KindLogValuer:
+		if r, ok := v.Any().(Redactor); ok {
+			return slog.AnyValue(r.Redact())
+		}
+		// Errors are rendered by their own outputs; resolve lazy values only.
+		if _, ok := v.Any().(error); !ok {
+			return o.slogValue(v.Resolve())
+		}
+	case slog.KindGroup:
+		attrs := make([]slog.Attr, 0, len(v.Group()))
+		for _, a := range v.Group() {
+			if o.matchKey(a.Key) {
+				a.Value = slog.StringValue(o.Replacement)
+			} else {
+				a.Value = o.slogValue(a.Value)
+			}
+			attrs = append(attrs, a)
+		}
+		return slog.GroupValue(attrs...)
+	}
+	return v
+}
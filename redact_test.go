@@ -0,0 +1,169 @@
+package e_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+)
+
+type creditCard struct{ Number string }
+
+func (c creditCard) Redact() any { return "****" + c.Number[len(c.Number)-4:] }
+
+func setRedaction(t *testing.T, opts *e.RedactOpts) {
+	t.Helper()
+	e.SetRedaction(opts)
+	t.Cleanup(func() { e.SetRedaction(nil) })
+}
+
+func redactedErr() error {
+	err := e.WrapWithMessage(errors.New("no user bob@example.com"), "lookup bob@example.com")
+	return e.WrapWithFields(err,
+		e.Field("password", "hunter2"),
+		e.String("X-Auth-Token", "abc"),
+		e.Field("card", creditCard{Number: "4111111111111111"}),
+		e.Group("req", e.String("authorization", "Bearer x"), e.String("path", "/login")),
+		e.Field("user", "alice"),
+	)
+}
+
+func TestRedaction_JSON(t *testing.T) {
+	setRedaction(t, &e.RedactOpts{
+		Keys:     e.DefaultRedactKeys,
+		Patterns: []*regexp.Regexp{e.EmailPattern},
+	})
+
+	data, err := json.Marshal(redactedErr())
+	require.NoError(t, err)
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(data, &m))
+
+	assert.Equal(t, "no user [REDACTED]", m["error"])
+	assert.Equal(t, "[REDACTED]", m["password"])
+	assert.Equal(t, "[REDACTED]", m["X-Auth-Token"])
+	assert.Equal(t, "****1111", m["card"])
+	assert.Equal(t, map[string]any{"authorization": "[REDACTED]", "path": "/login"}, m["req"])
+	assert.Equal(t, "alice", m["user"])
+	assert.NotContains(t, string(data), "bob@example.com")
+	assert.NotContains(t, string(data), "hunter2")
+}
+
+func TestRedaction_SlogAndFormat(t *testing.T) {
+	setRedaction(t, &e.RedactOpts{
+		Keys:        []string{"password"},
+		Patterns:    []*regexp.Regexp{e.EmailPattern},
+		Replacement: "***",
+	})
+	err := redactedErr()
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "err", err)
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.NotContains(t, buf.String(), "bob@example.com")
+	assert.Contains(t, buf.String(), `"password":"***"`)
+
+	report := fmt.Sprintf("%+v", err)
+	assert.NotContains(t, report, "hunter2")
+	assert.NotContains(t, report, "bob@example.com")
+	assert.NotContains(t, report, "4111111111111111")
+}
+
+func TestRedaction_NestedKeys(t *testing.T) {
+	setRedaction(t, &e.RedactOpts{Keys: e.DefaultRedactKeys})
+
+	err := e.WrapWithFields(errors.New("boom"),
+		e.Field("req", map[string]any{
+			"user":    "alice",
+			"body":    map[string]any{"password": "hunter2"},
+			"items":   []any{map[string]any{"api_key": "k-1"}},
+			"headers": map[string]string{"Authorization": "Bearer x", "Accept": "*/*"},
+		}),
+		e.FromAttrs(slog.Any("meta", map[string]any{"session_token": "t-1"})),
+	)
+	m := marshalMap(t, err)
+
+	assert.Equal(t, map[string]any{
+		"user":    "alice",
+		"body":    map[string]any{"password": "[REDACTED]"},
+		"items":   []any{map[string]any{"api_key": "[REDACTED]"}},
+		"headers": map[string]any{"Authorization": "[REDACTED]", "Accept": "*/*"},
+	}, m["req"])
+	assert.Equal(t, map[string]any{"session_token": "[REDACTED]"}, m["meta"])
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "err", err)
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.NotContains(t, buf.String(), "k-1")
+
+	v, _ := e.FieldOf(err, "req")
+	assert.Equal(t, "hunter2", v.(map[string]any)["body"].(map[string]any)["password"])
+}
+
+func TestRedaction_InternalMode(t *testing.T) {
+	setRedaction(t, &e.RedactOpts{Keys: []string{"password"}, Mode: e.RenderInternal})
+
+	report := fmt.Sprintf("%+v", redactedErr())
+	assert.Contains(t, report, "hunter2")
+	assert.Contains(t, report, "4111111111111111")
+}
+
+func TestRedaction_PerOutputMode(t *testing.T) {
+	setRedaction(t, &e.RedactOpts{Keys: []string{"password"}})
+	err := redactedErr()
+
+	var buf bytes.Buffer
+	slog.New(e.NewSlogHandler(slog.NewJSONHandler(&buf, nil), &e.SlogHandlerOpts{Mode: e.RenderInternal})).Error("failed", "err", err)
+	assert.Contains(t, buf.String(), "hunter2")
+
+	assert.Contains(t, e.Format(err, &e.FormatOpts{Mode: e.RenderInternal}), "hunter2")
+	assert.NotContains(t, e.Format(err, nil), "hunter2")
+
+	// An output can stay public while the global mode is internal.
+	setRedaction(t, &e.RedactOpts{Keys: []string{"password"}, Mode: e.RenderInternal})
+	path := filepath.Join(t.TempDir(), "errors.jsonl")
+	sink, serr := e.NewFileSink(path, &e.FileSinkOpts{Mode: e.RenderPublic})
+	require.NoError(t, serr)
+	require.NoError(t, sink.Send(context.Background(), []e.Event{{Err: err, Time: time.Now()}}))
+	require.NoError(t, sink.Close())
+
+	data, rerr := os.ReadFile(path)
+	require.NoError(t, rerr)
+	assert.Contains(t, string(data), `"password":"[REDACTED]"`)
+	assert.NotContains(t, string(data), "hunter2")
+	assert.Contains(t, e.Format(err, nil), "hunter2")
+}
+
+func TestRedaction_KeepsProgrammaticAccess(t *testing.T) {
+	setRedaction(t, &e.RedactOpts{Keys: []string{"password"}, Patterns: []*regexp.Regexp{e.EmailPattern}})
+	err := redactedErr()
+
+	v, _ := e.FieldOf(err, "password")
+	assert.Equal(t, "hunter2", v)
+	assert.Equal(t, "no user bob@example.com", err.Error())
+}
+
+func TestRedaction_RedactorWithoutRules(t *testing.T) {
+	data, err := json.Marshal(redactedErr())
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "4111111111111111")
+	assert.Contains(t, string(data), "hunter2")
+}
+
+func TestCardNumberPattern(t *testing.T) {
+	assert.Equal(t, "card [REDACTED] declined",
+		e.CardNumberPattern.ReplaceAllString("card 4111 1111 1111 1111 declined", "[REDACTED]"))
+	assert.Equal(t, "order 12345", e.CardNumberPattern.ReplaceAllString("order 12345", "[REDACTED]"))
+}
@@ -0,0 +1,325 @@
+package e
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// fatalFlushTimeout bounds how long the Fatal recovery path waits for the
+// default reporter to deliver pending errors before the process exits.
+const fatalFlushTimeout = 5 * time.Second
+
+// Event is a single reported error as seen by a Sink.
+type Event struct {
+	// Err is the reported error.
+	Err error
+
+	// Time is the moment the error was reported.
+	Time time.Time
+}
+
+// Sink delivers batches of reported errors to an external destination.
+//
+// Send is called from a single goroutine per Reporter. A non-nil error makes
+// the Reporter retry the same batch with backoff. Sinks must not retain the
+// events slice after Send returns.
+type Sink interface {
+	Send(ctx context.Context, events []Event) error
+}
+
+// ReporterOpts defines the queueing, batching and retry behavior of a Reporter.
+// Zero values select the defaults.
+type ReporterOpts struct {
+	// QueueSize is the maximum number of errors waiting to be sent.
+	// Errors reported while the queue is full are dropped. Default: 1024.
+	QueueSize int
+
+	// BatchSize is the maximum number of errors passed to a single Sink.Send call. Default: 64.
+	BatchSize int
+
+	// FlushInterval is how often a partial batch is sent. Default: 1s.
+	FlushInterval time.Duration
+
+	// MaxRetries is the number of times a failed batch is retried per sink.
+	// Default: 3. A negative value disables retries.
+	MaxRetries int
+
+	// Backoff is the delay before the first retry; it doubles after every
+	// attempt up to MaxBackoff. Defaults: 100ms and 5s.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// OnError, if set, is called with the last error of a batch that could not
+	// be delivered to a sink after all retries.
+	OnError func(error)
+}
+
+// withDefaults returns a copy of opts with zero values replaced by defaults.
+func (opts *ReporterOpts) withDefaults() ReporterOpts {
+	var o ReporterOpts
+	if opts != nil {
+		o = *opts
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 1024
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 64
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = time.Second
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = 3
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = 100 * time.Millisecond
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 5 * time.Second
+	}
+	return o
+}
+
+// ReporterStats is a snapshot of the counters of a Reporter.
+// Delivered and Failed count one per event and sink.
+type ReporterStats struct {
+	Enqueued  uint64
+	Dropped   uint64
+	Delivered uint64
+	Failed    uint64
+}
+
+// Reporter asynchronously delivers reported errors to one or more sinks.
+//
+// Errors are buffered in a bounded queue and sent in batches by a background
+// goroutine; failed batches are retried with exponential backoff. Call Flush
+// or Close on shutdown to deliver what is still queued.
+type Reporter struct {
+	opts  ReporterOpts
+	sinks []Sink
+
+	queue    chan Event
+	flushReq chan chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	closed    atomic.Bool
+	enqueued  atomic.Uint64
+	dropped   atomic.Uint64
+	delivered atomic.Uint64
+	failed    atomic.Uint64
+}
+
+// NewReporter starts a Reporter delivering to the given sinks.
+// A nil opts selects the defaults.
+func NewReporter(opts *ReporterOpts, sinks ...Sink) *Reporter {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := &Reporter{
+		opts:     opts.withDefaults(),
+		sinks:    sinks,
+		flushReq: make(chan chan struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	r.queue = make(chan Event, r.opts.QueueSize)
+
+	go r.run()
+	return r
+}
+
+// Report enqueues err for delivery without blocking.
+// If the queue is full or the reporter is closed, the error is dropped.
+// Nil errors are ignored.
+func (r *Reporter) Report(err error) {
+	if err == nil {
+		return
+	}
+	if r.closed.Load() {
+		r.dropped.Add(1)
+		return
+	}
+
+	// The worker renders the error later; take a copy that the caller's
+	// further wraps cannot modify.
+	select {
+	case r.queue <- Event{Err: freezeError(err), Time: time.Now()}:
+		r.enqueued.Add(1)
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Flush blocks until every error queued before the call has been handed to
+// the sinks (including retries), or ctx is done.
+func (r *Reporter) Flush(ctx context.Context) error {
+	ack := make(chan struct{})
+
+	select {
+	case r.flushReq <- ack:
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close flushes the queue and stops the background goroutine.
+// If ctx is done first, pending retries are abandoned and ctx.Err() is returned.
+// Errors reported after Close are dropped.
+func (r *Reporter) Close(ctx context.Context) error {
+	r.closed.Store(true)
+	r.stopOnce.Do(func() { close(r.stop) })
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		r.cancel()
+		<-r.done
+		return ctx.Err()
+	}
+}
+
+// Stats returns a snapshot of the reporter counters.
+func (r *Reporter) Stats() ReporterStats {
+	return ReporterStats{
+		Enqueued:  r.enqueued.Load(),
+		Dropped:   r.dropped.Load(),
+		Delivered: r.delivered.Load(),
+		Failed:    r.failed.Load(),
+	}
+}
+
+// run is the background loop that batches queued events and sends them.
+func (r *Reporter) run() {
+	defer close(r.done)
+	defer r.cancel()
+
+	ticker := time.NewTicker(r.opts.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []Event
+
+	for {
+		select {
+		case ev := <-r.queue:
+			batch = append(batch, ev)
+			if len(batch) >= r.opts.BatchSize {
+				r.send(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				r.send(batch)
+				batch = nil
+			}
+		case ack := <-r.flushReq:
+			r.drain(batch)
+			batch = nil
+			close(ack)
+		case <-r.stop:
+			r.drain(batch)
+			return
+		}
+	}
+}
+
+// drain sends batch together with everything currently in the queue.
+func (r *Reporter) drain(batch []Event) {
+	for {
+		select {
+		case ev := <-r.queue:
+			batch = append(batch, ev)
+			if len(batch) >= r.opts.BatchSize {
+				r.send(batch)
+				batch = nil
+			}
+		default:
+			if len(batch) > 0 {
+				r.send(batch)
+			}
+			return
+		}
+	}
+}
+
+// send delivers one batch to every sink, retrying with backoff.
+func (r *Reporter) send(batch []Event) {
+	for _, sink := range r.sinks {
+		if err := r.sendWithRetry(sink, batch); err != nil {
+			r.failed.Add(uint64(len(batch)))
+			if r.opts.OnError != nil {
+				r.opts.OnError(err)
+			}
+			continue
+		}
+		r.delivered.Add(uint64(len(batch)))
+	}
+}
+
+func (r *Reporter) sendWithRetry(sink Sink, batch []Event) error {
+	backoff := r.opts.Backoff
+
+	for attempt := 0; ; attempt++ {
+		err := sink.Send(r.ctx, batch)
+		if err == nil {
+			return nil
+		}
+		if attempt >= r.opts.MaxRetries || r.ctx.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-r.ctx.Done():
+			timer.Stop()
+			return errors.Join(err, r.ctx.Err())
+		}
+
+		backoff *= 2
+		if backoff > r.opts.MaxBackoff {
+			backoff = r.opts.MaxBackoff
+		}
+	}
+}
+
+// defaultReporter receives every error passed to the package-level Report.
+var defaultReporter atomic.Pointer[Reporter]
+
+// SetDefaultReporter makes Report forward errors to r in addition to
+// DefaultRecorder. A nil r disables forwarding.
+func SetDefaultReporter(r *Reporter) {
+	defaultReporter.Store(r)
+}
+
+// flushDefaultReporter gives the default reporter a bounded amount of time
+// to deliver queued errors. It is used before the process exits.
+func flushDefaultReporter() {
+	r := defaultReporter.Load()
+	if r == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fatalFlushTimeout)
+	defer cancel()
+	_ = r.Flush(ctx)
+}
@@ -0,0 +1,231 @@
+package e_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+)
+
+// memorySink collects events and can be told to fail a number of times.
+type memorySink struct {
+	mu       sync.Mutex
+	events   []e.Event
+	batches  int
+	failures int
+	block    chan struct{}
+	entered  chan struct{}
+}
+
+func (s *memorySink) Send(ctx context.Context, events []e.Event) error {
+	if s.entered != nil {
+		select {
+		case s.entered <- struct{}{}:
+		default:
+		}
+	}
+	if s.block != nil {
+		select {
+		case <-s.block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.batches++
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *memorySink) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.events)
+}
+
+func TestReporter_FlushDeliversInBatches(t *testing.T) {
+	sink := &memorySink{}
+	r := e.NewReporter(&e.ReporterOpts{BatchSize: 2, FlushInterval: time.Hour}, sink)
+	defer r.Close(context.Background())
+
+	for i := 0; i < 5; i++ {
+		r.Report(loadUser(i))
+	}
+	r.Report(nil)
+
+	require.NoError(t, r.Flush(context.Background()))
+
+	assert.Equal(t, 5, sink.count())
+	assert.Equal(t, 3, sink.batches)
+	assert.Equal(t, e.ReporterStats{Enqueued: 5, Delivered: 5}, r.Stats())
+}
+
+func TestReporter_FlushInterval(t *testing.T) {
+	sink := &memorySink{}
+	r := e.NewReporter(&e.ReporterOpts{FlushInterval: 10 * time.Millisecond}, sink)
+	defer r.Close(context.Background())
+
+	r.Report(loadUser(1))
+
+	assert.Eventually(t, func() bool { return sink.count() == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestReporter_RetriesWithBackoff(t *testing.T) {
+	sink := &memorySink{failures: 2}
+	r := e.NewReporter(&e.ReporterOpts{Backoff: time.Millisecond}, sink)
+	defer r.Close(context.Background())
+
+	r.Report(loadUser(1))
+	require.NoError(t, r.Flush(context.Background()))
+
+	assert.Equal(t, 1, sink.count())
+	assert.Equal(t, uint64(1), r.Stats().Delivered)
+}
+
+func TestReporter_GivesUpAfterMaxRetries(t *testing.T) {
+	var failures []error
+	sink := &memorySink{failures: 10}
+	r := e.NewReporter(&e.ReporterOpts{
+		MaxRetries: 2,
+		Backoff:    time.Millisecond,
+		OnError:    func(err error) { failures = append(failures, err) },
+	}, sink)
+	defer r.Close(context.Background())
+
+	r.Report(loadUser(1))
+	require.NoError(t, r.Flush(context.Background()))
+
+	assert.Zero(t, sink.count())
+	assert.Equal(t, 7, sink.failures, "expected one attempt plus two retries")
+	assert.Equal(t, uint64(1), r.Stats().Failed)
+	assert.Len(t, failures, 1)
+}
+
+func TestReporter_DropsWhenQueueFull(t *testing.T) {
+	sink := &memorySink{block: make(chan struct{}), entered: make(chan struct{}, 1)}
+	r := e.NewReporter(&e.ReporterOpts{QueueSize: 2, BatchSize: 1}, sink)
+
+	// The first error is picked up by the worker, which then blocks in the sink.
+	r.Report(loadUser(0))
+	<-sink.entered
+
+	for i := 1; i <= 5; i++ {
+		r.Report(loadUser(i))
+	}
+
+	assert.Equal(t, uint64(3), r.Stats().Dropped)
+
+	close(sink.block)
+	require.NoError(t, r.Close(context.Background()))
+	assert.Equal(t, 3, sink.count())
+
+	r.Report(loadUser(6))
+	assert.Equal(t, uint64(4), r.Stats().Dropped, "errors reported after Close are dropped")
+}
+
+func TestReporter_CloseHonorsContext(t *testing.T) {
+	sink := &memorySink{block: make(chan struct{})}
+	r := e.NewReporter(nil, sink)
+	r.Report(loadUser(1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, r.Close(ctx), context.DeadlineExceeded)
+}
+
+func TestReport_ForwardsToDefaultReporter(t *testing.T) {
+	sink := &memorySink{}
+	r := e.NewReporter(nil, sink)
+	e.SetDefaultReporter(r)
+	t.Cleanup(func() {
+		e.SetDefaultReporter(nil)
+		e.DefaultRecorder.Reset()
+		r.Close(context.Background())
+	})
+
+	e.Report(loadUser(1))
+	require.NoError(t, r.Flush(context.Background()))
+
+	assert.Equal(t, 1, sink.count())
+	assert.Equal(t, 1, e.DefaultRecorder.Len())
+}
+
+// renderingSink formats every event, like the JSON and Sentry sinks do.
+type renderingSink struct {
+	mu      sync.Mutex
+	reports []string
+}
+
+func (s *renderingSink) Send(_ context.Context, events []e.Event) error {
+	for _, ev := range events {
+		out := e.Format(ev.Err, nil)
+		s.mu.Lock()
+		s.reports = append(s.reports, out)
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// Run with -race: the caller keeps wrapping the error after reporting it.
+func TestReporter_SnapshotsErrorAtReport(t *testing.T) {
+	sink := &renderingSink{}
+	r := e.NewReporter(&e.ReporterOpts{FlushInterval: time.Millisecond}, sink)
+	defer r.Close(context.Background())
+
+	base := errors.New("boom")
+	err := e.WrapWithFields(base, e.Field("attempt", 1))
+	wrapped := fmt.Errorf("handler: %w", err)
+	r.Report(wrapped)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 100 {
+			_ = e.WrapWithFields(err, e.Field("outer", i))
+		}
+	}()
+	require.NoError(t, r.Flush(context.Background()))
+	wg.Wait()
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	require.Len(t, sink.reports, 1)
+	assert.Contains(t, sink.reports[0], "*fmt.wrapError: handler: boom")
+	assert.Contains(t, sink.reports[0], "attempt = 1")
+	assert.NotContains(t, sink.reports[0], "outer")
+}
+
+func TestFreezeError_KeepsIdentity(t *testing.T) {
+	base := errors.New("boom")
+	sink := &memorySink{}
+	r := e.NewReporter(nil, sink)
+	defer r.Close(context.Background())
+
+	r.Report(fmt.Errorf("handler: %w", e.Wrap(base)))
+	require.NoError(t, r.Flush(context.Background()))
+
+	require.Equal(t, 1, sink.count())
+	got := sink.events[0].Err
+	assert.EqualError(t, got, "handler: boom")
+	assert.True(t, errors.Is(got, base))
+
+	var ew *e.ErrorWrapper
+	assert.True(t, errors.As(got, &ew))
+	var wrapErr interface{ Unwrap() error }
+	assert.True(t, errors.As(got, &wrapErr))
+}
@@ -0,0 +1,284 @@
+package e
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SentrySinkOpts configures a SentrySink.
+type SentrySinkOpts struct {
+	// Client is used to send requests. Default: http.DefaultClient.
+	Client *http.Client
+
+	// Environment, Release and ServerName are copied into every event.
+	Environment string
+	Release     string
+	ServerName  string
+
+	// TagKeys lists the field keys sent as Sentry tags (indexed and searchable).
+	// All other fields are sent as extra data.
+	TagKeys []string
+
+	// Mode selects public or internal rendering for this sink.
+	// Default: RenderDefault, the mode set with SetRedaction.
+	Mode RenderMode
+}
+
+// SentrySink delivers errors to a Sentry (or Sentry-compatible) server
+// using the envelope endpoint. Each event is sent as its own envelope.
+type SentrySink struct {
+	endpoint  string
+	publicKey string
+	dsn       string
+	opts      SentrySinkOpts
+}
+
+// NewSentrySink returns a sink for the project identified by dsn, in the
+// form "https://<public_key>@<host>[/<path>]/<project_id>".
+// A nil opts selects the defaults.
+func NewSentrySink(dsn string, opts *SentrySinkOpts) (*SentrySink, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("e: invalid sentry dsn: %w", err)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, errors.New("e: invalid sentry dsn: missing public key")
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	projectID := path[i+1:]
+	if projectID == "" {
+		return nil, errors.New("e: invalid sentry dsn: missing project id")
+	}
+
+	var o SentrySinkOpts
+	if opts != nil {
+		o = *opts
+	}
+	if o.Client == nil {
+		o.Client = http.DefaultClient
+	}
+
+	endpoint := url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   path[:i] + "/api/" + projectID + "/envelope/",
+	}
+
+	return &SentrySink{
+		endpoint:  endpoint.String(),
+		publicKey: u.User.Username(),
+		dsn:       dsn,
+		opts:      o,
+	}, nil
+}
+
+// Send implements Sink.
+func (s *SentrySink) Send(ctx context.Context, events []Event) error {
+	var errs []error
+	for _, ev := range events {
+		if err := s.sendEvent(ctx, ev); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *SentrySink) sendEvent(ctx context.Context, ev Event) error {
+	body, err := s.envelope(ev)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", "Sentry sentry_version=7, sentry_client=whynot00-e/1.0, sentry_key="+s.publicKey)
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("e: sentry responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// envelope serializes ev as a Sentry envelope with a single event item.
+func (s *SentrySink) envelope(ev Event) ([]byte, error) {
+	event := s.event(ev)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := json.Marshal(map[string]any{
+		"event_id": event.EventID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+		"dsn":      s.dsn,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(header)
+	buf.WriteString("\n")
+	buf.WriteString(`{"type":"event","length":` + strconv.Itoa(len(payload)) + "}\n")
+	buf.Write(payload)
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// sentryEvent is the subset of the Sentry event payload produced by SentrySink.
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Exception   sentryExceptions  `json:"exception"`
+	Fingerprint []string          `json:"fingerprint"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]any    `json:"extra,omitempty"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type       string           `json:"type"`
+	Value      string           `json:"value"`
+	Stacktrace *sentryTrace     `json:"stacktrace,omitempty"`
+	Mechanism  *sentryMechanism `json:"mechanism,omitempty"`
+}
+
+type sentryTrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryFrame struct {
+	Function string            `json:"function"`
+	Filename string            `json:"filename"`
+	AbsPath  string            `json:"abs_path"`
+	Lineno   int               `json:"lineno"`
+	InApp    bool              `json:"in_app"`
+	Vars     map[string]string `json:"vars,omitempty"`
+}
+
+type sentryMechanism struct {
+	Type    string `json:"type"`
+	Handled bool   `json:"handled"`
+}
+
+// event converts ev into a Sentry event.
+func (s *SentrySink) event(ev Event) sentryEvent {
+	fp := Fingerprint(ev.Err)
+	o := renderOptsFor(s.opts.Mode)
+
+	out := sentryEvent{
+		EventID:     sentryEventID(fp, ev),
+		Timestamp:   ev.Time.UTC().Format(time.RFC3339Nano),
+		Platform:    "go",
+		Level:       "error",
+		Environment: s.opts.Environment,
+		Release:     s.opts.Release,
+		ServerName:  s.opts.ServerName,
+		Fingerprint: []string{fp},
+	}
+
+	// Group by code first, so Sentry keeps errors of different kinds apart
+	// even when they share a stack.
+	if code := CodeOf(ev.Err); code != "" {
+		out.Fingerprint = []string{string(code), fp}
+		out.Tags = map[string]string{"code": string(code)}
+	}
+
+	baseErr := ev.Err
+	exc := sentryException{
+		Mechanism: &sentryMechanism{Type: "generic", Handled: true},
+	}
+
+	var ew *ErrorWrapper
+	if errors.As(ev.Err, &ew) {
+		baseErr = ew.err
+
+		frames := make([]sentryFrame, 0, len(ew.frames))
+		for _, f := range ew.frames {
+			sf := sentryFrame{
+				Function: f.funcName,
+				Filename: f.path(),
+				AbsPath:  f.file,
+				Lineno:   f.line,
+				InApp:    true,
+			}
+			if f.message != "" {
+				sf.Vars = map[string]string{"message": o.rules.redactText(f.message)}
+			}
+			frames = append(frames, sf)
+		}
+
+		// Sentry expects the oldest frame first and the failing frame last.
+		// Wrap prepends outer call sites, so frames already follow that order,
+		// while panic stacks are captured innermost first.
+		if ew.panicked {
+			slices.Reverse(frames)
+			out.Level = "fatal"
+			exc.Mechanism = &sentryMechanism{Type: "panic", Handled: false}
+		}
+		if len(frames) > 0 {
+			exc.Stacktrace = &sentryTrace{Frames: frames}
+		}
+
+		for _, kv := range ew.renderFields(o.rules) {
+			if slices.Contains(s.opts.TagKeys, kv.Key) {
+				if out.Tags == nil {
+					out.Tags = map[string]string{}
+				}
+				out.Tags[kv.Key] = fmt.Sprint(kv.any())
+				continue
+			}
+			if out.Extra == nil {
+				out.Extra = map[string]any{}
+			}
+			out.Extra[kv.Key] = kv.encodeJSON(0, nil, o)
+		}
+	}
+
+	exc.Type = errorType(baseErr)
+	exc.Value = o.rules.redactText(baseErr.Error())
+	out.Exception.Values = []sentryException{exc}
+
+	return out
+}
+
+// sentryEventID derives a deterministic event ID, so a batch retried by the
+// Reporter is deduplicated by Sentry instead of creating duplicate events.
+func sentryEventID(fp string, ev Event) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%d\n%s", fp, ev.Time.UnixNano(), ev.Err.Error())
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
@@ -0,0 +1,211 @@
+package e_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+)
+
+type sentryRequest struct {
+	path   string
+	auth   string
+	header map[string]any
+	item   map[string]any
+	event  struct {
+		EventID     string            `json:"event_id"`
+		Level       string            `json:"level"`
+		Environment string            `json:"environment"`
+		Fingerprint []string          `json:"fingerprint"`
+		Tags        map[string]string `json:"tags"`
+		Extra       map[string]any    `json:"extra"`
+		Exception   struct {
+			Values []struct {
+				Type       string `json:"type"`
+				Value      string `json:"value"`
+				Stacktrace struct {
+					Frames []struct {
+						Function string            `json:"function"`
+						Filename string            `json:"filename"`
+						AbsPath  string            `json:"abs_path"`
+						Lineno   int               `json:"lineno"`
+						Vars     map[string]string `json:"vars"`
+					} `json:"frames"`
+				} `json:"stacktrace"`
+				Mechanism struct {
+					Type    string `json:"type"`
+					Handled bool   `json:"handled"`
+				} `json:"mechanism"`
+			} `json:"values"`
+		} `json:"exception"`
+	}
+}
+
+// newSentryServer starts a local stand-in for a Sentry server that records envelopes.
+func newSentryServer(t *testing.T) (*httptest.Server, func() []sentryRequest) {
+	var (
+		mu   sync.Mutex
+		reqs []sentryRequest
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req sentryRequest
+		req.path = r.URL.Path
+		req.auth = r.Header.Get("X-Sentry-Auth")
+
+		sc := bufio.NewScanner(r.Body)
+		sc.Buffer(nil, 1<<20)
+		require.True(t, sc.Scan())
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &req.header))
+		require.True(t, sc.Scan())
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &req.item))
+		require.True(t, sc.Scan())
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &req.event))
+		assert.Equal(t, float64(len(sc.Bytes())), req.item["length"])
+
+		mu.Lock()
+		reqs = append(reqs, req)
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() []sentryRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]sentryRequest(nil), reqs...)
+	}
+}
+
+func sentryDSN(srv *httptest.Server) string {
+	return strings.Replace(srv.URL, "http://", "http://pubkey@", 1) + "/sentry/42"
+}
+
+func TestNewSentrySink_InvalidDSN(t *testing.T) {
+	for _, dsn := range []string{"http://host/42", "http://key@host", "::"} {
+		_, err := e.NewSentrySink(dsn, nil)
+		assert.Error(t, err, dsn)
+	}
+}
+
+func TestSentrySink_WrappedError(t *testing.T) {
+	srv, requests := newSentryServer(t)
+
+	sink, err := e.NewSentrySink(sentryDSN(srv), &e.SentrySinkOpts{
+		Environment: "test",
+		TagKeys:     []string{"tenant"},
+	})
+	require.NoError(t, err)
+
+	inner := e.WrapWithMessage(errors.New("no rows"), "loading user")
+	outer := e.WrapWithFields(inner, e.Field("tenant", "foo"), e.Field("user_id", 42))
+
+	ev := e.Event{Err: outer, Time: time.Now()}
+	require.NoError(t, sink.Send(context.Background(), []e.Event{ev}))
+
+	reqs := requests()
+	require.Len(t, reqs, 1)
+	req := reqs[0]
+
+	assert.Equal(t, "/sentry/api/42/envelope/", req.path)
+	assert.Contains(t, req.auth, "sentry_key=pubkey")
+	assert.Equal(t, "event", req.item["type"])
+	assert.Equal(t, req.header["event_id"], req.event.EventID)
+
+	assert.Equal(t, "error", req.event.Level)
+	assert.Equal(t, "test", req.event.Environment)
+	assert.Equal(t, []string{e.Fingerprint(outer)}, req.event.Fingerprint)
+	assert.Equal(t, map[string]string{"tenant": "foo"}, req.event.Tags)
+	assert.Equal(t, map[string]any{"user_id": float64(42)}, req.event.Extra)
+
+	require.Len(t, req.event.Exception.Values, 1)
+	exc := req.event.Exception.Values[0]
+	assert.Equal(t, "*errors.errorString", exc.Type)
+	assert.Equal(t, "no rows", exc.Value)
+	assert.True(t, exc.Mechanism.Handled)
+
+	// Oldest frame first: the outer wrap, then the inner one that carries the message.
+	frames := exc.Stacktrace.Frames
+	require.Len(t, frames, 2)
+	assert.Greater(t, frames[0].Lineno, frames[1].Lineno)
+	assert.Empty(t, frames[0].Vars)
+	assert.Equal(t, "loading user", frames[1].Vars["message"])
+}
+
+func TestSentrySink_RecoveredPanic(t *testing.T) {
+	srv, requests := newSentryServer(t)
+
+	sink, err := e.NewSentrySink(sentryDSN(srv), nil)
+	require.NoError(t, err)
+
+	var recovered error
+	func() {
+		defer e.Recover(nil, func(err error) { recovered = err })
+		panic("kaboom")
+	}()
+
+	require.NoError(t, sink.Send(context.Background(), []e.Event{{Err: recovered, Time: time.Now()}}))
+
+	reqs := requests()
+	require.Len(t, reqs, 1)
+
+	exc := reqs[0].event.Exception.Values[0]
+	assert.Equal(t, "fatal", reqs[0].event.Level)
+	assert.Equal(t, "panic", exc.Mechanism.Type)
+	assert.False(t, exc.Mechanism.Handled)
+
+	// The panicking test function must be the last (newest) frame.
+	frames := exc.Stacktrace.Frames
+	require.NotEmpty(t, frames)
+	assert.Equal(t, "func1", frames[len(frames)-1].Function)
+	assert.True(t, strings.HasSuffix(frames[len(frames)-1].AbsPath, "sentry_test.go"))
+}
+
+func TestSentrySink_FramePaths(t *testing.T) {
+	e.SetPathOpts(&e.PathOpts{ModuleRelative: true})
+	t.Cleanup(func() { e.SetPathOpts(nil) })
+
+	srv, requests := newSentryServer(t)
+
+	sink, err := e.NewSentrySink(sentryDSN(srv), nil)
+	require.NoError(t, err)
+
+	wrapped := e.Wrap(errors.New("boom"))
+	require.NoError(t, sink.Send(context.Background(), []e.Event{{Err: wrapped, Time: time.Now()}}))
+
+	reqs := requests()
+	require.Len(t, reqs, 1)
+
+	// filename follows PathOpts, abs_path stays the absolute build path.
+	frames := reqs[0].event.Exception.Values[0].Stacktrace.Frames
+	require.Len(t, frames, 1)
+	assert.Equal(t, "sentry_test.go", frames[0].Filename)
+	assert.True(t, filepath.IsAbs(frames[0].AbsPath), frames[0].AbsPath)
+	assert.True(t, strings.HasSuffix(frames[0].AbsPath, "/sentry_test.go"))
+}
+
+func TestSentrySink_DeterministicEventID(t *testing.T) {
+	srv, requests := newSentryServer(t)
+
+	sink, err := e.NewSentrySink(sentryDSN(srv), nil)
+	require.NoError(t, err)
+
+	ev := e.Event{Err: loadUser(1), Time: time.Now()}
+	require.NoError(t, sink.Send(context.Background(), []e.Event{ev}))
+	require.NoError(t, sink.Send(context.Background(), []e.Event{ev}))
+
+	reqs := requests()
+	require.Len(t, reqs, 2)
+	assert.Equal(t, reqs[0].event.EventID, reqs[1].event.EventID)
+	assert.Len(t, reqs[0].event.EventID, 32)
+}
@@ -0,0 +1,266 @@
+package e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// eventJSON builds the JSON object written by the built-in sinks for one event:
+// the same object as ErrorWrapper.MarshalJSON plus the report time, rendered
+// in mode.
+func eventJSON(ev Event, mode RenderMode) map[string]any {
+	var out map[string]any
+
+	o := renderOptsFor(mode)
+	var ew *ErrorWrapper
+	if errors.As(ev.Err, &ew) {
+		out = ew.jsonMapAt(0, nil, o)
+	} else {
+		out = map[string]any{
+			"error":       o.rules.redactText(ev.Err.Error()),
+			"fingerprint": Fingerprint(ev.Err),
+		}
+	}
+
+	out["time"] = ev.Time.Format(time.RFC3339Nano)
+	return out
+}
+
+// WriterSink writes every event as one JSON object per line to an io.Writer.
+// It renders in the mode set with SetRedaction.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink returns a sink writing JSON lines to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// NewStdoutSink returns a sink writing JSON lines to os.Stdout.
+func NewStdoutSink() *WriterSink {
+	return NewWriterSink(os.Stdout)
+}
+
+// Send implements Sink.
+func (s *WriterSink) Send(_ context.Context, events []Event) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, ev := range events {
+		if err := enc.Encode(eventJSON(ev, RenderDefault)); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.w.Write(buf.Bytes())
+	return err
+}
+
+// FileSinkOpts defines the rotation policy of a FileSink.
+// Zero values select the defaults.
+type FileSinkOpts struct {
+	// MaxSize is the size in bytes after which the file is rotated. Default: 10 MiB.
+	MaxSize int64
+
+	// MaxBackups is the number of rotated files kept as path.1 … path.N. Default: 3.
+	MaxBackups int
+
+	// Mode selects public or internal rendering for this sink.
+	// Default: RenderDefault, the mode set with SetRedaction.
+	Mode RenderMode
+}
+
+// FileSink appends events as JSON lines to a file, rotating it by size.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+	opts FileSinkOpts
+	f    *os.File
+	size int64
+}
+
+// NewFileSink opens (or creates) the file at path for appending.
+// A nil opts selects the defaults.
+func NewFileSink(path string, opts *FileSinkOpts) (*FileSink, error) {
+	var o FileSinkOpts
+	if opts != nil {
+		o = *opts
+	}
+	if o.MaxSize <= 0 {
+		o.MaxSize = 10 << 20
+	}
+	if o.MaxBackups <= 0 {
+		o.MaxBackups = 3
+	}
+
+	s := &FileSink{path: path, opts: o}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Send implements Sink.
+func (s *FileSink) Send(_ context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		return errors.New("e: file sink is closed")
+	}
+
+	for _, ev := range events {
+		line, err := json.Marshal(eventJSON(ev, s.opts.Mode))
+		if err != nil {
+			return err
+		}
+		line = append(line, '\n')
+
+		if s.size > 0 && s.size+int64(len(line)) > s.opts.MaxSize {
+			if err := s.rotate(); err != nil {
+				return err
+			}
+		}
+
+		n, err := s.f.Write(line)
+		s.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the underlying file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.f, s.size = f, info.Size()
+	return nil
+}
+
+// rotate shifts path.N-1 → path.N, …, path → path.1 and reopens path.
+// The caller must hold s.mu.
+//
+// path is reopened even if the rotation fails, so the sink keeps working
+// (appending to the current file) and retries the rotation on the next write.
+func (s *FileSink) rotate() error {
+	err := s.f.Close()
+	s.f = nil
+	if err == nil {
+		err = s.shiftBackups()
+	}
+	return errors.Join(err, s.open())
+}
+
+// shiftBackups renames the backups and the current file one step up.
+func (s *FileSink) shiftBackups() error {
+	for i := s.opts.MaxBackups - 1; i >= 1; i-- {
+		src := s.path + "." + strconv.Itoa(i)
+		if _, err := os.Stat(src); err == nil {
+			if err := os.Rename(src, s.path+"."+strconv.Itoa(i+1)); err != nil {
+				return err
+			}
+		}
+	}
+	return os.Rename(s.path, s.path+".1")
+}
+
+// WebhookSinkOpts configures a WebhookSink.
+type WebhookSinkOpts struct {
+	// Client is used to send requests. Default: http.DefaultClient.
+	Client *http.Client
+
+	// Header is added to every request, e.g. for authorization.
+	Header http.Header
+
+	// Mode selects public or internal rendering for this sink.
+	// Default: RenderDefault, the mode set with SetRedaction.
+	Mode RenderMode
+}
+
+// WebhookSink posts every batch as a JSON array to an HTTP endpoint.
+// Any non-2xx response is treated as a failure and retried by the Reporter.
+type WebhookSink struct {
+	url  string
+	opts WebhookSinkOpts
+}
+
+// NewWebhookSink returns a sink posting to url. A nil opts selects the defaults.
+func NewWebhookSink(url string, opts *WebhookSinkOpts) *WebhookSink {
+	var o WebhookSinkOpts
+	if opts != nil {
+		o = *opts
+	}
+	if o.Client == nil {
+		o.Client = http.DefaultClient
+	}
+	return &WebhookSink{url: url, opts: o}
+}
+
+// Send implements Sink.
+func (s *WebhookSink) Send(ctx context.Context, events []Event) error {
+	payload := make([]map[string]any, 0, len(events))
+	for _, ev := range events {
+		payload = append(payload, eventJSON(ev, s.opts.Mode))
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, vs := range s.opts.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("e: webhook responded with %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package e_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+)
+
+func testEvents(n int) []e.Event {
+	events := make([]e.Event, n)
+	for i := range events {
+		events[i] = e.Event{
+			Err:  e.WrapWithFields(errors.New("boom"), e.Field("i", i)),
+			Time: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		}
+	}
+	return events
+}
+
+func TestWriterSink_JSONLines(t *testing.T) {
+	var sb strings.Builder
+	sink := e.NewWriterSink(&sb)
+
+	require.NoError(t, sink.Send(context.Background(), testEvents(2)))
+
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var obj map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &obj))
+	assert.Equal(t, "boom", obj["error"])
+	assert.Equal(t, float64(1), obj["i"])
+	assert.Equal(t, "2025-01-02T03:04:05Z", obj["time"])
+	assert.NotEmpty(t, obj["stack_trace"])
+}
+
+func TestFileSink_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.jsonl")
+
+	sink, err := e.NewFileSink(path, &e.FileSinkOpts{MaxSize: 1, MaxBackups: 2})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	// With a 1-byte limit every event after the first one rotates the file.
+	for _, ev := range testEvents(4) {
+		require.NoError(t, sink.Send(context.Background(), []e.Event{ev}))
+	}
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, 1, countLines(t, path))
+	assert.Equal(t, 1, countLines(t, path+".1"))
+	assert.Equal(t, 1, countLines(t, path+".2"))
+	assert.NoFileExists(t, path+".3")
+
+	// The newest event stays in the active file.
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"i":3`)
+}
+
+func TestFileSink_RotationFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.jsonl")
+
+	// A non-empty directory in place of the first backup makes the rename fail.
+	require.NoError(t, os.MkdirAll(filepath.Join(path+".1", "blocker"), 0o755))
+
+	sink, err := e.NewFileSink(path, &e.FileSinkOpts{MaxSize: 1, MaxBackups: 1})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	events := testEvents(3)
+	require.NoError(t, sink.Send(context.Background(), events[:1]))
+	assert.Error(t, sink.Send(context.Background(), events[1:2]))
+
+	// The sink stays open and rotates once the backup path is usable again.
+	require.NoError(t, os.RemoveAll(path+".1"))
+	require.NoError(t, sink.Send(context.Background(), events[2:]))
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, 1, countLines(t, path))
+	assert.Equal(t, 1, countLines(t, path+".1"))
+}
+
+func TestFileSink_Closed(t *testing.T) {
+	sink, err := e.NewFileSink(filepath.Join(t.TempDir(), "errors.jsonl"), nil)
+	require.NoError(t, err)
+	require.NoError(t, sink.Close())
+
+	assert.Error(t, sink.Send(context.Background(), testEvents(1)))
+}
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var n int
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		n++
+	}
+	return n
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer srv.Close()
+
+	sink := e.NewWebhookSink(srv.URL, &e.WebhookSinkOpts{
+		Header: http.Header{"Authorization": {"Bearer secret"}},
+	})
+
+	require.NoError(t, sink.Send(context.Background(), testEvents(3)))
+	assert.Len(t, got, 3)
+}
+
+func TestWebhookSink_RetriedThroughReporter(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	r := e.NewReporter(&e.ReporterOpts{Backoff: time.Millisecond}, e.NewWebhookSink(srv.URL, nil))
+	r.Report(loadUser(1))
+	require.NoError(t, r.Close(context.Background()))
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, uint64(1), r.Stats().Delivered)
+}
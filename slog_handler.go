@@ -0,0 +1,167 @@
+package e
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+)
+
+// SlogHandlerOpts configures the handler returned by NewSlogHandler.
+type SlogHandlerOpts struct {
+	// LiftFingerprint adds a "fingerprint" attribute to the record for the
+	// first wrapped error found in it, so it can be indexed without
+	// descending into the error group. Lifted attributes are added at the
+	// top level, outside the groups opened with WithGroup.
+	LiftFingerprint bool
+
+	// LiftCode adds a "code" attribute to the record for the first wrapped
+	// error found in it, if that error has a code.
+	LiftCode bool
+
+	// Mode selects public or internal rendering for this handler.
+	// Default: RenderDefault, the mode set with SetRedaction.
+	Mode RenderMode
+}
+
+// NewSlogHandler returns a slog.Handler that expands errors before passing
+// records to next.
+//
+// Every attribute whose value is an error wrapping an *ErrorWrapper (at the
+// top level or nested in groups) is replaced by the group SlogGroup would
+// build for it. When the same wrapped error appears more than once in a
+// record, only the first occurrence is expanded; the others are logged as
+// their error text. A nil opts selects the defaults.
+func NewSlogHandler(next slog.Handler, opts *SlogHandlerOpts) slog.Handler {
+	h := &slogHandler{next: next}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+type slogHandler struct {
+	next slog.Handler
+	opts SlogHandlerOpts
+
+	// groups holds the groups opened with WithGroup while an attribute is
+	// lifted, with the attributes added to each. They are applied to the
+	// record in Handle rather than to next, so lifted attributes stay at the
+	// top level.
+	groups []openGroup
+}
+
+// openGroup is a group opened with WithGroup and the attributes added to it.
+type openGroup struct {
+	name  string
+	attrs []slog.Attr
+}
+
+// lifts reports whether attributes are lifted to the top level of records.
+func (h *slogHandler) lifts() bool {
+	return h.opts.LiftFingerprint || h.opts.LiftCode
+}
+
+// Enabled implements slog.Handler.
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	exp := &errorExpander{seen: map[*ErrorWrapper]bool{}, opts: renderOptsFor(h.opts.Mode)}
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, exp.expand(a))
+		return true
+	})
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		g := h.groups[i]
+		attrs = []slog.Attr{{Key: g.name, Value: slog.GroupValue(append(slices.Clip(g.attrs), attrs...)...)}}
+	}
+
+	out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	out.AddAttrs(attrs...)
+
+	if h.opts.LiftFingerprint && exp.first != nil {
+		out.AddAttrs(slog.String("fingerprint", Fingerprint(exp.first)))
+	}
+	if h.opts.LiftCode && exp.first != nil {
+		if code := CodeOf(exp.first); code != "" {
+			out.AddAttrs(slog.String("code", string(code)))
+		}
+	}
+
+	return h.next.Handle(ctx, out)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	exp := &errorExpander{seen: map[*ErrorWrapper]bool{}, opts: renderOptsFor(h.opts.Mode)}
+
+	expanded := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		expanded[i] = exp.expand(a)
+	}
+	if len(h.groups) > 0 {
+		groups := slices.Clone(h.groups)
+		last := &groups[len(groups)-1]
+		last.attrs = append(slices.Clip(last.attrs), expanded...)
+		return &slogHandler{next: h.next, opts: h.opts, groups: groups}
+	}
+	return &slogHandler{next: h.next.WithAttrs(expanded), opts: h.opts}
+}
+
+// WithGroup implements slog.Handler.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	if h.lifts() {
+		groups := append(slices.Clip(h.groups), openGroup{name: name})
+		return &slogHandler{next: h.next, opts: h.opts, groups: groups}
+	}
+	return &slogHandler{next: h.next.WithGroup(name), opts: h.opts}
+}
+
+// errorExpander rewrites error attributes of a single record.
+type errorExpander struct {
+	seen  map[*ErrorWrapper]bool
+	first error
+	opts  renderOpts
+}
+
+// expand returns a with wrapped errors replaced by their group.
+func (x *errorExpander) expand(a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			attrs[i] = x.expand(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}
+
+	case slog.KindAny, slog.KindLogValuer:
+		err, ok := a.Value.Any().(error)
+		if !ok || err == nil {
+			return a
+		}
+
+		var ew *ErrorWrapper
+		if !errors.As(err, &ew) {
+			return a
+		}
+		if x.seen[ew] {
+			return slog.String(a.Key, x.opts.rules.redactText(err.Error()))
+		}
+		x.seen[ew] = true
+		if x.first == nil {
+			x.first = err
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(slogAttrsAt(err, 0, nil, x.opts)...)}
+	}
+
+	return a
+}
@@ -0,0 +1,119 @@
+package e_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+)
+
+func newExpandingLogger(opts *e.SlogHandlerOpts) (*slog.Logger, *strings.Builder) {
+	var buf strings.Builder
+	h := e.NewSlogHandler(slog.NewJSONHandler(&buf, nil), opts)
+	return slog.New(h), &buf
+}
+
+func decodeLogLine(t *testing.T, buf *strings.Builder) map[string]any {
+	t.Helper()
+
+	var out map[string]any
+	require.NoError(t, json.Unmarshal([]byte(buf.String()), &out), buf.String())
+	return out
+}
+
+func TestSlogHandler_ExpandsChainedError(t *testing.T) {
+	logger, buf := newExpandingLogger(nil)
+
+	// fmt.Errorf hides the wrapper from slog.LogValuer, the handler still finds it.
+	err := fmt.Errorf("handler: %w", e.WrapWithFields(errors.New("no rows"), e.Field("user_id", 42)))
+	logger.Error("request failed", "err", err)
+
+	out := decodeLogLine(t, buf)
+	group, ok := out["err"].(map[string]any)
+	require.True(t, ok, "err was not expanded: %v", out["err"])
+	assert.Equal(t, "handler: no rows", group["error_text"])
+	assert.Equal(t, "no rows", group["base_error_text"])
+	assert.Equal(t, float64(42), group["user_id"])
+	assert.NotEmpty(t, group["stack_trace"])
+}
+
+func TestSlogHandler_ExpandsNestedInGroups(t *testing.T) {
+	logger, buf := newExpandingLogger(nil)
+
+	err := fmt.Errorf("wrapped: %w", e.Wrap(errors.New("boom")))
+	logger.Error("failed", slog.Group("req", slog.String("id", "r-1"), slog.Any("cause", err)))
+
+	out := decodeLogLine(t, buf)
+	req := out["req"].(map[string]any)
+	cause, ok := req["cause"].(map[string]any)
+	require.True(t, ok, "nested error was not expanded: %v", req["cause"])
+	assert.Equal(t, "wrapped: boom", cause["error_text"])
+	assert.Equal(t, "boom", cause["base_error_text"])
+}
+
+func TestSlogHandler_LeavesPlainErrors(t *testing.T) {
+	logger, buf := newExpandingLogger(nil)
+
+	logger.Error("failed", "err", errors.New("plain"))
+
+	assert.Equal(t, "plain", decodeLogLine(t, buf)["err"])
+}
+
+func TestSlogHandler_Dedupes(t *testing.T) {
+	logger, buf := newExpandingLogger(nil)
+
+	err := e.Wrap(errors.New("boom"))
+	logger.Error("failed", "err", err, "cause", fmt.Errorf("again: %w", err))
+
+	out := decodeLogLine(t, buf)
+	assert.IsType(t, map[string]any{}, out["err"])
+	assert.Equal(t, "again: boom", out["cause"])
+}
+
+func TestSlogHandler_WithAttrs(t *testing.T) {
+	logger, buf := newExpandingLogger(nil)
+
+	err := fmt.Errorf("startup: %w", e.Wrap(errors.New("boom")))
+	logger.With("init_err", err).Info("running degraded")
+
+	out := decodeLogLine(t, buf)
+	assert.IsType(t, map[string]any{}, out["init_err"])
+}
+
+func TestSlogHandler_LiftFingerprint(t *testing.T) {
+	logger, buf := newExpandingLogger(&e.SlogHandlerOpts{LiftFingerprint: true})
+
+	err := loadUser(1)
+	logger.Error("failed", "err", err)
+
+	assert.Equal(t, e.Fingerprint(err), decodeLogLine(t, buf)["fingerprint"])
+}
+
+func TestSlogHandler_LiftsOutOfGroups(t *testing.T) {
+	logger, buf := newExpandingLogger(&e.SlogHandlerOpts{LiftFingerprint: true, LiftCode: true})
+
+	err := e.WithCode(errors.New("no rows"), e.NotFound)
+	logger.With("svc", "api").WithGroup("req").With("id", "r-1").WithGroup("db").Error("failed", "err", err)
+
+	out := decodeLogLine(t, buf)
+	assert.Equal(t, e.Fingerprint(err), out["fingerprint"])
+	assert.Equal(t, "not_found", out["code"])
+	assert.Equal(t, "api", out["svc"])
+
+	req, ok := out["req"].(map[string]any)
+	require.True(t, ok, "req = %v", out["req"])
+	assert.Equal(t, "r-1", req["id"])
+	assert.NotContains(t, req, "fingerprint")
+
+	db, ok := req["db"].(map[string]any)
+	require.True(t, ok, "db = %v", req["db"])
+	group, ok := db["err"].(map[string]any)
+	require.True(t, ok, "err was not expanded: %v", db["err"])
+	assert.Equal(t, "no rows", group["error_text"])
+}
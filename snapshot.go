@@ -0,0 +1,127 @@
+package e
+
+import (
+	"log/slog"
+	"reflect"
+	"sync/atomic"
+	"unsafe"
+)
+
+// maxSnapshotDepth bounds the recursion of snapshots of deeply nested
+// values. Containers that contain themselves are handled by snapshotter.
+const maxSnapshotDepth = 32
+
+// fieldSnapshots reports whether field values are deep-copied when attached.
+var fieldSnapshots atomic.Bool
+
+// SetFieldSnapshots enables or disables snapshot mode.
+//
+// By default field values are stored by reference, so a map or slice mutated
+// after WrapWithFields shows its later contents when the error is rendered.
+// In snapshot mode maps, slices and arrays (and the containers nested in them)
+// are deep-copied when the fields are attached to an error. Other values,
+// including pointers and structs, are still stored as is. Lazy values are
+// never copied, as they are meant to be evaluated at render time.
+func SetFieldSnapshots(enabled bool) {
+	fieldSnapshots.Store(enabled)
+}
+
+// snapshotFields returns list with every container value deep-copied.
+func snapshotFields(list []KeyValue) []KeyValue {
+	out := make([]KeyValue, len(list))
+	for i, kv := range list {
+		if kv.typed {
+			if kv.val.Kind() == slog.KindAny {
+				kv.val = slog.AnyValue(snapshotValue(kv.val.Any()))
+			}
+		} else {
+			kv.Value = snapshotValue(kv.Value)
+		}
+		out[i] = kv
+	}
+	return out
+}
+
+// snapshotValue deep-copies v if it is a map, slice or array.
+func snapshotValue(v any) any {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		sn := snapshotter{copies: map[visitKey]reflect.Value{}}
+		return sn.deepCopy(rv, 0).Interface()
+	}
+	return v
+}
+
+// snapshotter deep-copies a single value. It remembers the copy of every
+// map and slice, so a container referenced several times, including by
+// itself, is copied once and the copy keeps the shape of the original.
+type snapshotter struct {
+	copies map[visitKey]reflect.Value
+}
+
+// visitKey identifies a map or slice; slices sharing an array differ in length.
+type visitKey struct {
+	ptr unsafe.Pointer
+	typ reflect.Type
+	len int
+}
+
+func (s *snapshotter) deepCopy(v reflect.Value, depth int) reflect.Value {
+	if depth >= maxSnapshotDepth {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		key := visitKey{v.UnsafePointer(), v.Type(), 0}
+		if cp, ok := s.copies[key]; ok {
+			return cp
+		}
+		cp := reflect.MakeMapWithSize(v.Type(), v.Len())
+		s.copies[key] = cp
+		iter := v.MapRange()
+		for iter.Next() {
+			cp.SetMapIndex(iter.Key(), s.deepCopy(iter.Value(), depth+1))
+		}
+		return cp
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		key := visitKey{v.UnsafePointer(), v.Type(), v.Len()}
+		if cp, ok := s.copies[key]; ok {
+			return cp
+		}
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		s.copies[key] = cp
+		for i := range v.Len() {
+			cp.Index(i).Set(s.deepCopy(v.Index(i), depth+1))
+		}
+		return cp
+
+	case reflect.Array:
+		cp := reflect.New(v.Type()).Elem()
+		for i := range v.Len() {
+			cp.Index(i).Set(s.deepCopy(v.Index(i), depth+1))
+		}
+		return cp
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(s.deepCopy(v.Elem(), depth+1))
+		return cp
+	}
+
+	return v
+}
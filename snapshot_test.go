@@ -0,0 +1,93 @@
+package e_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whynot00/e"
+)
+
+func TestFieldSnapshots_Disabled(t *testing.T) {
+	tags := []string{"a"}
+	err := e.WrapWithFields(errors.New("boom"), e.Field("tags", tags))
+	tags[0] = "changed"
+
+	v, _ := e.FieldOf(err, "tags")
+	assert.Equal(t, []string{"changed"}, v)
+}
+
+func TestFieldSnapshots_Enabled(t *testing.T) {
+	e.SetFieldSnapshots(true)
+	t.Cleanup(func() { e.SetFieldSnapshots(false) })
+
+	tags := []string{"a"}
+	meta := map[string]any{"ids": []int{1, 2}}
+	arr := [2][]int{{1}, {2}}
+	typed := map[string]int{"n": 1}
+	err := e.WrapWithFields(errors.New("boom"),
+		e.Field("tags", tags),
+		e.Field("meta", meta),
+		e.Field("arr", arr),
+		e.FromAttrs(slog.Any("typed", typed)),
+	)
+
+	tags[0] = "changed"
+	meta["ids"].([]int)[0] = 99
+	meta["new"] = true
+	arr[0][0] = 99
+	typed["n"] = 2
+
+	v, _ := e.FieldOf(err, "tags")
+	assert.Equal(t, []string{"a"}, v)
+
+	v, _ = e.FieldOf(err, "meta")
+	assert.Equal(t, map[string]any{"ids": []int{1, 2}}, v)
+
+	v, _ = e.FieldOf(err, "arr")
+	assert.Equal(t, [2][]int{{1}, {2}}, v)
+
+	v, _ = e.FieldOf(err, "typed")
+	assert.Equal(t, map[string]int{"n": 1}, v)
+}
+
+func TestFieldSnapshots_SelfReferencing(t *testing.T) {
+	e.SetFieldSnapshots(true)
+	t.Cleanup(func() { e.SetFieldSnapshots(false) })
+
+	loop := []any{nil}
+	loop[0] = loop
+
+	assert.NotPanics(t, func() {
+		_ = e.WrapWithFields(errors.New("boom"), e.Field("loop", loop))
+	})
+}
+
+func TestFieldSnapshots_SharedAndSelfReferences(t *testing.T) {
+	e.SetFieldSnapshots(true)
+	t.Cleanup(func() { e.SetFieldSnapshots(false) })
+
+	// Without tracking visited containers this copies 3^32 maps.
+	m := map[string]any{"id": 1}
+	m["a"], m["b"], m["c"] = m, m, m
+
+	done := make(chan error)
+	go func() { done <- e.WrapWithFields(errors.New("boom"), e.Field("m", m)) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("snapshot did not finish")
+	}
+
+	m["id"] = 2
+
+	v, _ := e.FieldOf(err, "m")
+	cp := v.(map[string]any)
+	assert.Equal(t, 1, cp["id"])
+	assert.Equal(t, 1, cp["a"].(map[string]any)["id"])
+	assert.Equal(t, 1, cp["c"].(map[string]any)["b"].(map[string]any)["id"])
+}
@@ -0,0 +1,164 @@
+package e
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// SnippetOpts configures source snippet capture, a development aid that shows
+// the code around each frame in Format, %+v and the console handler.
+// Zero values select the defaults.
+type SnippetOpts struct {
+	// Context is the number of lines shown before and after the frame line. Default: 2.
+	Context int
+
+	// MaxFileSize is the size in bytes above which a file is not read. Default: 1 MiB.
+	MaxFileSize int64
+
+	// MaxCachedFiles bounds the number of source files kept in memory. Default: 64.
+	MaxCachedFiles int
+}
+
+// snippetOpts holds the options set with SetSnippets; nil disables snippets.
+var snippetOpts atomic.Pointer[SnippetOpts]
+
+// SetSnippets enables source snippets for frames. Source files are read on
+// first use and cached. A nil opts disables snippets and drops the cache.
+//
+// Snippets are meant for development: the source files must be present on
+// the machine running the binary.
+func SetSnippets(opts *SnippetOpts) {
+	sourceCache.reset()
+
+	if opts == nil {
+		snippetOpts.Store(nil)
+		return
+	}
+
+	cp := *opts
+	if cp.Context <= 0 {
+		cp.Context = 2
+	}
+	if cp.MaxFileSize <= 0 {
+		cp.MaxFileSize = 1 << 20
+	}
+	if cp.MaxCachedFiles <= 0 {
+		cp.MaxCachedFiles = 64
+	}
+	snippetOpts.Store(&cp)
+}
+
+// snippetLine is one line of source shown around a frame.
+type snippetLine struct {
+	number  int
+	text    string
+	current bool
+}
+
+// snippet returns the source lines around f, or nil if snippets are
+// disabled or the file cannot be read.
+func (f frame) snippet() []snippetLine {
+	opts := snippetOpts.Load()
+	if opts == nil || f.line <= 0 {
+		return nil
+	}
+
+	lines := sourceCache.get(f.file, opts)
+	if f.line > len(lines) {
+		return nil
+	}
+
+	from := max(f.line-opts.Context, 1)
+	to := min(f.line+opts.Context, len(lines))
+
+	out := make([]snippetLine, 0, to-from+1)
+	for n := from; n <= to; n++ {
+		out = append(out, snippetLine{number: n, text: lines[n-1], current: n == f.line})
+	}
+	return out
+}
+
+// writeSnippet renders the snippet of f, each line prefixed with indent.
+func writeSnippet(sb *strings.Builder, indent string, f frame, p painter) {
+	lines := f.snippet()
+	if len(lines) == 0 {
+		return
+	}
+
+	width := len(fmt.Sprint(lines[len(lines)-1].number))
+	for _, l := range lines {
+		text := strings.ReplaceAll(l.text, "\t", "    ")
+		if l.current {
+			fmt.Fprintf(sb, "%s%s\n", indent, p.paint(ansiYellow, fmt.Sprintf("> %*d | %s", width, l.number, text)))
+			continue
+		}
+		fmt.Fprintf(sb, "%s%s\n", indent, p.paint(ansiGray, fmt.Sprintf("  %*d | %s", width, l.number, text)))
+	}
+}
+
+// sourceCache is the process-wide cache of source files read for snippets.
+var sourceCache = &fileCache{}
+
+// fileCache keeps the lines of recently read source files.
+// A nil entry records a file that could not be read or was too large.
+type fileCache struct {
+	mu    sync.Mutex
+	files map[string][]string
+	order []string
+}
+
+func (c *fileCache) get(path string, opts *SnippetOpts) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if lines, ok := c.files[path]; ok {
+		return lines
+	}
+
+	lines := readSourceLines(path, opts.MaxFileSize)
+
+	if c.files == nil {
+		c.files = map[string][]string{}
+	}
+	if len(c.order) >= opts.MaxCachedFiles {
+		delete(c.files, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.files[path] = lines
+	c.order = append(c.order, path)
+
+	return lines
+}
+
+func (c *fileCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files, c.order = nil, nil
+}
+
+// readSourceLines returns the lines of the file at path, or nil if it
+// cannot be read or exceeds maxSize.
+func readSourceLines(path string, maxSize int64) []string {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > maxSize {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), int(maxSize)+1)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	return lines
+}
@@ -0,0 +1,51 @@
+package e_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whynot00/e"
+)
+
+func snippetError() error {
+	// line before the wrap
+	return e.Wrap(errors.New("boom")) // snippet marker
+}
+
+func TestSnippets_InFormat(t *testing.T) {
+	e.SetSnippets(&e.SnippetOpts{Context: 1})
+	t.Cleanup(func() { e.SetSnippets(nil) })
+
+	out := fmt.Sprintf("%+v", snippetError())
+
+	assert.Regexp(t, `\n\s+\d+ \|     // line before the wrap\n`, out)
+	assert.Regexp(t, `\n\s+> \d+ \|     return e\.Wrap\(errors\.New\("boom"\)\) // snippet marker\n`, out)
+	assert.Regexp(t, `\n\s+\d+ \| }`, out)
+	assert.Equal(t, out, e.Format(snippetError(), nil))
+}
+
+func TestSnippets_InConsole(t *testing.T) {
+	e.SetSnippets(nil)
+	e.SetSnippets(&e.SnippetOpts{})
+	t.Cleanup(func() { e.SetSnippets(nil) })
+
+	var buf strings.Builder
+	slog.New(e.NewConsoleHandler(&buf, nil)).Error("failed", "err", snippetError())
+
+	assert.Contains(t, buf.String(), "// snippet marker")
+}
+
+func TestSnippets_DisabledByDefault(t *testing.T) {
+	assert.NotContains(t, e.Format(snippetError(), nil), "snippet marker")
+}
+
+func TestSnippets_FileTooLarge(t *testing.T) {
+	e.SetSnippets(&e.SnippetOpts{MaxFileSize: 10})
+	t.Cleanup(func() { e.SetSnippets(nil) })
+
+	assert.NotContains(t, e.Format(snippetError(), nil), "snippet marker")
+}
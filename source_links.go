@@ -0,0 +1,136 @@
+package e
+
+import (
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Common source link templates for SourceLinkOpts.Template.
+const (
+	GitHubSourceTemplate = "https://{module}/blob/{revision}/{path}#L{line}"
+	GitLabSourceTemplate = "https://{module}/-/blob/{revision}/{path}#L{line}"
+)
+
+// SourceLinkOpts configures the source_url attached to frames of the main module.
+type SourceLinkOpts struct {
+	// Template is the URL of a source line. It may contain the placeholders
+	// {module} (main module path), {revision}, {path} (file relative to the
+	// module root) and {line}. See GitHubSourceTemplate and GitLabSourceTemplate.
+	Template string
+
+	// Revision overrides the vcs.revision recorded in the build info,
+	// e.g. for binaries built with -buildvcs=false.
+	Revision string
+
+	// Repository replaces {module} when the repository URL differs from the
+	// module path. Default: the module path without its major version suffix
+	// (/v2, /v3, …), cut to host/owner/repo on github.com and bitbucket.org.
+	Repository string
+
+	// Dir is the directory of the module within the repository, prepended to
+	// {path}. Default: the rest of the module path on github.com and
+	// bitbucket.org, e.g. "tools" for github.com/acme/mono/tools.
+	Dir string
+}
+
+// sourceLinks holds the options set with SetSourceLinks; nil disables links.
+var sourceLinks atomic.Pointer[SourceLinkOpts]
+
+// SetSourceLinks enables a source_url for every frame of the main module in
+// the JSON and slog output, built from debug.ReadBuildInfo (main module path
+// and vcs.revision) and opts.Template. A nil opts disables source links.
+func SetSourceLinks(opts *SourceLinkOpts) {
+	if opts == nil {
+		sourceLinks.Store(nil)
+		return
+	}
+	cp := *opts
+	sourceLinks.Store(&cp)
+}
+
+// vcsRevision returns the vcs.revision build setting of the running binary.
+var vcsRevision = sync.OnceValue(func() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, s := range bi.Settings {
+		if s.Key == "vcs.revision" {
+			return s.Value
+		}
+	}
+	return ""
+})
+
+// sourceURL returns the link to f's source line, or an empty string if
+// links are disabled, the revision is unknown or f is outside the main module.
+func (f frame) sourceURL() string {
+	opts := sourceLinks.Load()
+	if opts == nil || opts.Template == "" {
+		return ""
+	}
+
+	revision := opts.Revision
+	if revision == "" {
+		revision = vcsRevision()
+	}
+	if revision == "" {
+		return ""
+	}
+
+	rel, ok := moduleRelativePath(f.file, f.function)
+	if !ok {
+		return ""
+	}
+	modPath, _ := mainModule()
+	repo, dir := sourceRepo(modPath)
+	if opts.Repository != "" {
+		repo = opts.Repository
+	}
+	if opts.Dir != "" {
+		dir = opts.Dir
+	}
+	if dir = strings.Trim(dir, "/"); dir != "" {
+		rel = dir + "/" + rel
+	}
+
+	return strings.NewReplacer(
+		"{module}", repo,
+		"{revision}", revision,
+		"{path}", rel,
+		"{line}", strconv.Itoa(f.line),
+	).Replace(opts.Template)
+}
+
+// sourceRepo splits the module path modPath into the repository it is hosted
+// in and the directory of the module within that repository.
+//
+// The major version suffix is dropped: github.com/acme/api/v2 is usually the
+// v2 branch of github.com/acme/api rather than a directory. Only hosts with
+// fixed host/owner/repo paths yield a directory, other hosts may nest groups.
+func sourceRepo(modPath string) (repo, dir string) {
+	if i := strings.LastIndexByte(modPath, '/'); i != -1 && isMajorSuffix(modPath[i+1:]) {
+		modPath = modPath[:i]
+	}
+
+	parts := strings.Split(modPath, "/")
+	switch parts[0] {
+	case "github.com", "bitbucket.org":
+		if len(parts) > 3 {
+			return strings.Join(parts[:3], "/"), strings.Join(parts[3:], "/")
+		}
+	}
+	return modPath, ""
+}
+
+// isMajorSuffix reports whether elem is a major version path element, such as "v2".
+func isMajorSuffix(elem string) bool {
+	if len(elem) < 2 || elem[0] != 'v' {
+		return false
+	}
+	n, err := strconv.Atoi(elem[1:])
+	return err == nil && n >= 2 && elem[1] != '0'
+}
@@ -0,0 +1,71 @@
+package e_test
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whynot00/e"
+)
+
+func TestSourceLinks(t *testing.T) {
+	e.SetSourceLinks(&e.SourceLinkOpts{Template: e.GitHubSourceTemplate, Revision: "abc123"})
+	t.Cleanup(func() { e.SetSourceLinks(nil) })
+
+	err := e.Wrap(errors.New("boom"))
+	link := regexp.MustCompile(`^https://github\.com/whynot00/e/blob/abc123/source_links_test\.go#L\d+$`)
+
+	data, errJSON := json.Marshal(err)
+	require.NoError(t, errJSON)
+
+	var out struct {
+		StackTrace []struct {
+			SourceURL string `json:"source_url"`
+		} `json:"stack_trace"`
+	}
+	require.NoError(t, json.Unmarshal(data, &out))
+	require.Len(t, out.StackTrace, 1)
+	assert.Regexp(t, link, out.StackTrace[0].SourceURL)
+
+	for _, a := range e.SlogGroup(err).Value.Group() {
+		if a.Key == "stack_trace" {
+			assert.Regexp(t, link, a.Value.Any().([]map[string]any)[0]["source_url"])
+		}
+	}
+}
+
+func TestSourceLinks_RepositoryAndDir(t *testing.T) {
+	e.SetSourceLinks(&e.SourceLinkOpts{
+		Template:   e.GitHubSourceTemplate,
+		Revision:   "abc123",
+		Repository: "github.com/whynot00/mono",
+		Dir:        "libs/e",
+	})
+	t.Cleanup(func() { e.SetSourceLinks(nil) })
+
+	data, err := json.Marshal(e.Wrap(errors.New("boom")))
+	require.NoError(t, err)
+
+	assert.Regexp(t, `https://github\.com/whynot00/mono/blob/abc123/libs/e/source_links_test\.go#L\d+`, string(data))
+}
+
+func TestSourceLinks_DisabledByDefault(t *testing.T) {
+	data, err := json.Marshal(e.Wrap(errors.New("boom")))
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(data), "source_url")
+}
+
+func TestSourceLinks_NoRevision(t *testing.T) {
+	// Test binaries carry no vcs.revision, so without an override no link is produced.
+	e.SetSourceLinks(&e.SourceLinkOpts{Template: e.GitLabSourceTemplate})
+	t.Cleanup(func() { e.SetSourceLinks(nil) })
+
+	data, err := json.Marshal(e.Wrap(errors.New("boom")))
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(data), "source_url")
+}
@@ -8,9 +8,24 @@ import (
 // frame represents a single captured stack frame in the trace.
 type frame struct {
 	funcName string
+	function string // fully qualified function name, used to resolve module-relative paths
 	file     string
 	line     int
 	message  string
+	fields   []KeyValue // fields attached at this wrap site
+}
+
+// fieldsMap returns the fields of f, redacted with rules, as an object with values
+// converted by value, or nil if the frame has none.
+func (f frame) fieldsMap(rules *RedactOpts, value func(KeyValue) any) map[string]any {
+	if len(f.fields) == 0 {
+		return nil
+	}
+	m := make(map[string]any, len(f.fields))
+	for _, kv := range rules.redactFields(f.fields) {
+		m[kv.Key] = value(kv)
+	}
+	return m
 }
 
 // simplifyFuncName trims package and receiver prefixes from a function name.
@@ -48,6 +63,7 @@ func captureStackTrace() []frame {
 
 		trace = append(trace, frame{
 			funcName: simplifyFuncName(fr.Function),
+			function: fr.Function,
 			file:     fr.File,
 			line:     fr.Line,
 		})
@@ -0,0 +1,95 @@
+package e
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+const (
+	// superviseBufferSize is the amount of trailing stderr kept for parsing a crash.
+	superviseBufferSize = 64 * 1024
+
+	// superviseTailSize is the amount of trailing stderr attached to the error as a field.
+	superviseTailSize = 4 * 1024
+)
+
+// Supervise runs the child process described by cmd and waits for it to exit.
+//
+// The child's stderr is captured (and still forwarded to cmd.Stderr if set).
+// If the child crashes with a Go panic or fatal error, Supervise returns an
+// *ErrorWrapper whose message and stack trace are taken from the child's
+// traceback, with the exit code and the tail of stderr attached as fields.
+//
+// Any other failure is wrapped with the current call site and the same fields.
+// A child that exits successfully yields nil.
+func Supervise(cmd *exec.Cmd) error {
+	tail := &tailBuffer{limit: superviseBufferSize}
+	if cmd.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(cmd.Stderr, tail)
+	} else {
+		cmd.Stderr = tail
+	}
+
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		// The process could not be started or waited for: nothing to parse.
+		return wrapWithSkip(err, 2, "", nil)
+	}
+
+	stderr := tail.Bytes()
+	flds := Fields{list: []KeyValue{
+		{Key: "command", Value: cmd.Path},
+		{Key: "exit_code", Value: exitErr.ExitCode()},
+		{Key: "stderr_tail", Value: lastBytes(stderr, superviseTailSize)},
+	}}
+
+	tb, ok := parseTraceback(stderr)
+	if !ok {
+		return wrapWithSkip(err, 2, "", &flds)
+	}
+
+	return &ErrorWrapper{
+		err:      errors.New(tb.message),
+		frames:   tb.frames,
+		fields:   &flds,
+		panicked: true,
+	}
+}
+
+// tailBuffer is an io.Writer that keeps only the last limit bytes written to it.
+type tailBuffer struct {
+	buf   []byte
+	limit int
+}
+
+// Write appends p, discarding the oldest bytes once the limit is exceeded.
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.limit; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+// Bytes returns the retained bytes.
+func (t *tailBuffer) Bytes() []byte { return t.buf }
+
+// lastBytes returns at most n trailing bytes of b as a string,
+// starting at a line boundary when one is available.
+func lastBytes(b []byte, n int) string {
+	if len(b) <= n {
+		return string(b)
+	}
+	s := string(b[len(b)-n:])
+	if i := strings.IndexByte(s, '\n'); i != -1 && i+1 < len(s) {
+		s = s[i+1:]
+	}
+	return s
+}
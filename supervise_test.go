@@ -0,0 +1,89 @@
+package e
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestSuperviseHelperProcess is not a real test: it is the child process
+// started by the Supervise tests.
+func TestSuperviseHelperProcess(t *testing.T) {
+	switch os.Getenv("E_SUPERVISE_HELPER") {
+	case "panic":
+		done := make(chan struct{})
+		go func() {
+			panic("child exploded")
+		}()
+		<-done
+	case "exit":
+		os.Stderr.WriteString("plain failure\n")
+		os.Exit(3)
+	case "ok":
+		os.Exit(0)
+	}
+}
+
+func helperCommand(mode string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSuperviseHelperProcess$")
+	cmd.Env = append(os.Environ(), "E_SUPERVISE_HELPER="+mode)
+	return cmd
+}
+
+func TestSupervise_Panic(t *testing.T) {
+	err := Supervise(helperCommand("panic"))
+
+	var ew *ErrorWrapper
+	if !errors.As(err, &ew) {
+		t.Fatalf("want *ErrorWrapper, got %T (%v)", err, err)
+	}
+	if ew.Error() != "panic: child exploded" {
+		t.Errorf("unexpected message: %q", ew.Error())
+	}
+	if code := ew.Fields().Get("exit_code"); code != 2 {
+		t.Errorf("exit_code = %v; want 2", code)
+	}
+	if tail, _ := ew.Fields().Get("stderr_tail").(string); !strings.Contains(tail, "child exploded") {
+		t.Errorf("stderr_tail does not contain the panic: %q", tail)
+	}
+
+	stack := ew.StackTrace()
+	if len(stack) == 0 || !strings.HasSuffix(stack[0].file, "supervise_test.go") {
+		t.Errorf("panic site is not the first frame: %+v", stack)
+	}
+}
+
+func TestSupervise_PlainExit(t *testing.T) {
+	err := Supervise(helperCommand("exit"))
+
+	var ew *ErrorWrapper
+	if !errors.As(err, &ew) {
+		t.Fatalf("want *ErrorWrapper, got %T (%v)", err, err)
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Error("expected the original *exec.ExitError to be preserved")
+	}
+	if code := ew.Fields().Get("exit_code"); code != 3 {
+		t.Errorf("exit_code = %v; want 3", code)
+	}
+}
+
+func TestSupervise_Success(t *testing.T) {
+	if err := Supervise(helperCommand("ok")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTailBuffer_KeepsLastBytes(t *testing.T) {
+	tb := &tailBuffer{limit: 4}
+	tb.Write([]byte("abc"))
+	tb.Write([]byte("def"))
+
+	if got := string(tb.Bytes()); got != "cdef" {
+		t.Errorf("got %q, want %q", got, "cdef")
+	}
+}
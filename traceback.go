@@ -0,0 +1,135 @@
+package e
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// traceback is the result of parsing the crash output of a Go program.
+type traceback struct {
+	message string
+	frames  []frame
+}
+
+// parseTraceback looks for a Go panic or fatal error in the given output
+// (usually the stderr of a crashed process) and extracts the message and
+// the stack of the first goroutine dump that follows it.
+//
+// It returns false if no panic or fatal error header is found.
+func parseTraceback(output []byte) (traceback, bool) {
+	var (
+		tb       traceback
+		found    bool
+		inStack  bool
+		pendingF string
+	)
+
+	sc := bufio.NewScanner(bytes.NewReader(output))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+
+		if !found {
+			if msg, ok := tracebackHeader(line); ok {
+				tb.message = msg
+				found = true
+			}
+			continue
+		}
+
+		if !inStack {
+			if strings.HasPrefix(line, "goroutine ") && strings.HasSuffix(line, ":") {
+				inStack = true
+			}
+			continue
+		}
+
+		// A blank line terminates the dump of the first goroutine.
+		if strings.TrimSpace(line) == "" {
+			break
+		}
+
+		if strings.HasPrefix(line, "\t") {
+			if pendingF == "" {
+				continue
+			}
+			file, lineNo := parseTracebackLocation(line)
+			if !isInternalTracebackFrame(pendingF, file) {
+				tb.frames = append(tb.frames, frame{
+					funcName: simplifyFuncName(pendingF),
+					function: pendingF,
+					file:     file,
+					line:     lineNo,
+				})
+			}
+			pendingF = ""
+			continue
+		}
+
+		pendingF = parseTracebackFunc(line)
+	}
+
+	return tb, found
+}
+
+// isInternalTracebackFrame is isInternalFrame for traceback frames, where the
+// runtime's panic entry point is printed as a bare "panic" function.
+func isInternalTracebackFrame(function, file string) bool {
+	return function == "panic" ||
+		strings.Contains(filepath.ToSlash(file), "/src/runtime/") ||
+		isInternalFrame(function)
+}
+
+// tracebackHeader reports whether line starts a Go crash report
+// and returns the message it carries.
+func tracebackHeader(line string) (string, bool) {
+	for _, prefix := range []string{"panic: ", "fatal error: "} {
+		if strings.HasPrefix(line, prefix) {
+			// "panic: boom [recovered]" is printed when a panic is re-raised.
+			if i := strings.Index(line, " [recovered"); i != -1 {
+				line = line[:i]
+			}
+			return line, true
+		}
+	}
+	return "", false
+}
+
+// parseTracebackFunc extracts the fully qualified function name from
+// a function line such as "main.(*T).Run(0xc000010000, 0x1)" or
+// "created by main.main in goroutine 1".
+func parseTracebackFunc(line string) string {
+	line = strings.TrimPrefix(line, "created by ")
+	if i := strings.Index(line, " in goroutine "); i != -1 {
+		line = line[:i]
+	}
+	if strings.HasSuffix(line, ")") {
+		if i := strings.LastIndex(line, "("); i > 0 {
+			line = line[:i]
+		}
+	}
+	return line
+}
+
+// parseTracebackLocation extracts file and line from a location line
+// such as "\t/app/main.go:42 +0x1d".
+func parseTracebackLocation(line string) (string, int) {
+	line = strings.TrimSpace(line)
+	if i := strings.LastIndex(line, " +0x"); i != -1 {
+		line = line[:i]
+	}
+
+	i := strings.LastIndex(line, ":")
+	if i == -1 {
+		return line, 0
+	}
+	n, err := strconv.Atoi(line[i+1:])
+	if err != nil {
+		return line, 0
+	}
+	return line[:i], n
+}
@@ -0,0 +1,96 @@
+package e
+
+import (
+	"reflect"
+	"testing"
+)
+
+const samplePanic = `some log line
+panic: boom
+
+goroutine 1 [running]:
+panic({0x4a2f80?, 0x4e1c08?})
+	/usr/local/go/src/runtime/panic.go:770 +0x132
+main.(*worker).run(0xc000012345, 0x2)
+	/app/worker.go:42 +0x1d
+main.main()
+	/app/main.go:10 +0x25
+runtime.goexit()
+	/usr/local/go/src/runtime/asm_amd64.s:1700 +0x1
+
+goroutine 5 [chan receive]:
+main.other()
+	/app/other.go:3 +0x10
+exit status 2
+`
+
+func TestParseTraceback_Panic(t *testing.T) {
+	tb, ok := parseTraceback([]byte(samplePanic))
+	if !ok {
+		t.Fatal("expected traceback to be found")
+	}
+	if tb.message != "panic: boom" {
+		t.Errorf("message = %q; want %q", tb.message, "panic: boom")
+	}
+
+	want := []frame{
+		{funcName: "run", function: "main.(*worker).run", file: "/app/worker.go", line: 42},
+		{funcName: "main", function: "main.main", file: "/app/main.go", line: 10},
+	}
+	if len(tb.frames) != len(want) {
+		t.Fatalf("got %d frames, want %d: %+v", len(tb.frames), len(want), tb.frames)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(tb.frames[i], want[i]) {
+			t.Errorf("frame %d = %+v; want %+v", i, tb.frames[i], want[i])
+		}
+	}
+}
+
+func TestParseTraceback_FatalError(t *testing.T) {
+	out := "fatal error: all goroutines are asleep - deadlock!\n\ngoroutine 1 [chan receive]:\nmain.main()\n\t/app/main.go:7 +0x2d\n"
+
+	tb, ok := parseTraceback([]byte(out))
+	if !ok {
+		t.Fatal("expected traceback to be found")
+	}
+	if tb.message != "fatal error: all goroutines are asleep - deadlock!" {
+		t.Errorf("unexpected message: %q", tb.message)
+	}
+	if len(tb.frames) != 1 || tb.frames[0].line != 7 {
+		t.Errorf("unexpected frames: %+v", tb.frames)
+	}
+}
+
+func TestParseTraceback_Recovered(t *testing.T) {
+	out := "panic: boom [recovered]\n\tpanic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:7 +0x2d\n"
+
+	tb, ok := parseTraceback([]byte(out))
+	if !ok || tb.message != "panic: boom" {
+		t.Errorf("unexpected traceback: %+v, %v", tb, ok)
+	}
+}
+
+func TestParseTraceback_NoCrash(t *testing.T) {
+	if _, ok := parseTraceback([]byte("error: file not found\nexit status 1\n")); ok {
+		t.Error("expected no traceback")
+	}
+}
+
+func TestParseTracebackFunc(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"main.main()", "main.main"},
+		{"main.(*T).Run(0xc000010000, 0x1)", "main.(*T).Run"},
+		{"created by main.main in goroutine 1", "main.main"},
+		{"github.com/user/pkg.Func(...)", "github.com/user/pkg.Func"},
+	}
+
+	for _, tt := range tests {
+		if got := parseTracebackFunc(tt.line); got != tt.want {
+			t.Errorf("parseTracebackFunc(%q) = %q; want %q", tt.line, got, tt.want)
+		}
+	}
+}